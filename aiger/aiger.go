@@ -0,0 +1,368 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+/*
+Package aiger reads and-inverter graphs in the AIGER format and checks their
+bad-state properties by bounded model checking.
+
+Both the ASCII ("aag") and binary ("aig") formats are read, including latches
+with initial values, outputs, bad-state properties and invariant constraints
+introduced in AIGER 1.9.  Justice and fairness properties are not supported.
+
+The combinational logic of a graph is Tseitin encoded into any dpll.Solver by
+an Encoder, one time frame at a time.  BMC unrolls the transition relation
+incrementally and reports counterexamples as witnesses in the AIGER witness
+format.
+*/
+package aiger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// Lit is an AIGER literal.  The variable of a literal is half its value and
+// odd literals are negated.  Literals 0 and 1 are the constants false and
+// true.
+type Lit uint32
+
+// Available constant literals.
+const (
+	False Lit = 0
+	True  Lit = 1
+)
+
+// Var returns the variable of x.
+func (x Lit) Var() int {
+	return int(x >> 1)
+}
+
+// IsNeg returns true if x is negated.
+func (x Lit) IsNeg() bool {
+	return x&1 == 1
+}
+
+// Inverse returns the negation of x.
+func (x Lit) Inverse() Lit {
+	return x ^ 1
+}
+
+// Latch is a state element.  Next is the value of the latch in the following
+// time frame.  Init is the initial value of the latch, False, True, or Lit if
+// the latch is uninitialized.
+type Latch struct {
+	Lit  Lit
+	Next Lit
+	Init Lit
+}
+
+// And is an AND gate defining Lit as the conjunction of In0 and In1.
+type And struct {
+	Lit Lit
+	In0 Lit
+	In1 Lit
+}
+
+// AIG is an and-inverter graph.  Constraints are literals assumed true in
+// every time frame.
+type AIG struct {
+	MaxVar      int
+	Inputs      []Lit
+	Latches     []Latch
+	Outputs     []Lit
+	Bad         []Lit
+	Constraints []Lit
+	Ands        []And
+}
+
+// DecodeFile opens path with dimacs.Open, so compressed files are accepted,
+// and decodes its contents using Decode.
+func DecodeFile(path string) (*AIG, error) {
+	f, err := dimacs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(f)
+}
+
+// Decode decodes a graph in the ASCII or binary AIGER format, which is
+// determined by the header.  The symbol table and comments are ignored.
+func Decode(r io.Reader) (*AIG, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	g, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	err = g.check()
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+type decoder struct {
+	r      *bufio.Reader
+	line   int
+	maxLit Lit
+}
+
+func (d *decoder) errorf(format string, v ...interface{}) error {
+	return fmt.Errorf("aiger: line %d: %s", d.line, fmt.Sprintf(format, v...))
+}
+
+// readLine reads the next line, without its terminator.
+func (d *decoder) readLine() (string, error) {
+	d.line++
+	s, err := d.r.ReadString('\n')
+	if err == io.EOF && s != "" {
+		err = nil
+	}
+	if err == io.EOF {
+		return "", d.errorf("unexpected end of input")
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(s, "\r\n"), nil
+}
+
+// readLits reads a line containing between min and max literals.
+func (d *decoder) readLits(min, max int) ([]Lit, error) {
+	s, err := d.readLine()
+	if err != nil {
+		return nil, err
+	}
+	fs := strings.Fields(s)
+	if len(fs) < min || len(fs) > max {
+		return nil, d.errorf("expected %d literals: %q", min, s)
+	}
+	xs := make([]Lit, len(fs))
+	for i, f := range fs {
+		x, err := strconv.ParseUint(f, 10, 32)
+		if err != nil || Lit(x) > d.maxLit {
+			return nil, d.errorf("invalid literal: %q", f)
+		}
+		xs[i] = Lit(x)
+	}
+	return xs, nil
+}
+
+func (d *decoder) decode() (*AIG, error) {
+	s, err := d.readLine()
+	if err != nil {
+		return nil, err
+	}
+	fs := strings.Fields(s)
+	if len(fs) < 6 || len(fs) > 10 || (fs[0] != "aag" && fs[0] != "aig") {
+		return nil, d.errorf("invalid header: %q", s)
+	}
+	isBinary := fs[0] == "aig"
+	var n [9]int // M I L O A B C J F
+	for i, f := range fs[1:] {
+		n[i], err = strconv.Atoi(f)
+		if err != nil || n[i] < 0 || n[i] >= 1<<31-1 {
+			return nil, d.errorf("invalid header: %q", s)
+		}
+	}
+	m, ni, nl, no, na, nb, nc := n[0], n[1], n[2], n[3], n[4], n[5], n[6]
+	if n[7] != 0 || n[8] != 0 {
+		return nil, d.errorf("justice and fairness properties are not supported")
+	}
+	if m < ni+nl+na || (isBinary && m != ni+nl+na) {
+		return nil, d.errorf("invalid maximum variable index: %d", m)
+	}
+	d.maxLit = Lit(2*m + 1)
+
+	g := &AIG{MaxVar: m}
+	for i := 0; i < ni; i++ {
+		if isBinary {
+			g.Inputs = append(g.Inputs, Lit(2*(i+1)))
+			continue
+		}
+		xs, err := d.readLits(1, 1)
+		if err != nil {
+			return nil, err
+		}
+		g.Inputs = append(g.Inputs, xs[0])
+	}
+	for i := 0; i < nl; i++ {
+		var l Latch
+		if isBinary {
+			xs, err := d.readLits(1, 2)
+			if err != nil {
+				return nil, err
+			}
+			l.Lit = Lit(2 * (ni + i + 1))
+			l.Next = xs[0]
+			if len(xs) > 1 {
+				l.Init = xs[1]
+			}
+		} else {
+			xs, err := d.readLits(2, 3)
+			if err != nil {
+				return nil, err
+			}
+			l.Lit, l.Next = xs[0], xs[1]
+			if len(xs) > 2 {
+				l.Init = xs[2]
+			}
+		}
+		if l.Init != False && l.Init != True && l.Init != l.Lit {
+			return nil, d.errorf("invalid latch initial value: %d", l.Init)
+		}
+		g.Latches = append(g.Latches, l)
+	}
+	for _, x := range []struct {
+		n    int
+		lits *[]Lit
+	}{
+		{no, &g.Outputs},
+		{nb, &g.Bad},
+		{nc, &g.Constraints},
+	} {
+		for i := 0; i < x.n; i++ {
+			xs, err := d.readLits(1, 1)
+			if err != nil {
+				return nil, err
+			}
+			*x.lits = append(*x.lits, xs[0])
+		}
+	}
+	for i := 0; i < na; i++ {
+		if !isBinary {
+			xs, err := d.readLits(3, 3)
+			if err != nil {
+				return nil, err
+			}
+			g.Ands = append(g.Ands, And{xs[0], xs[1], xs[2]})
+			continue
+		}
+		a := And{Lit: Lit(2 * (ni + nl + i + 1))}
+		var delta1 uint64
+		delta0, err := binary.ReadUvarint(d.r)
+		if err == nil {
+			delta1, err = binary.ReadUvarint(d.r)
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("aiger: and gate %d: %v", a.Lit, err)
+		}
+		if delta0 == 0 || delta0 > uint64(a.Lit) || delta1 > uint64(a.Lit)-delta0 {
+			return nil, fmt.Errorf("aiger: and gate %d: invalid deltas", a.Lit)
+		}
+		a.In0 = a.Lit - Lit(delta0)
+		a.In1 = a.In0 - Lit(delta1)
+		g.Ands = append(g.Ands, a)
+	}
+	return g, nil
+}
+
+// check verifies that every variable is defined once and used only if it is
+// defined, and that the AND gates contain no cycle.
+func (g *AIG) check() error {
+	const (
+		undef = iota
+		input
+		latch
+		and
+	)
+	kind := make([]int, g.MaxVar+1)
+	gate := make([]int, g.MaxVar+1)
+	define := func(x Lit, k int) error {
+		if x.IsNeg() || x.Var() == 0 {
+			return fmt.Errorf("aiger: invalid definition of literal %d", x)
+		}
+		if kind[x.Var()] != undef {
+			return fmt.Errorf("aiger: variable %d defined twice", x.Var())
+		}
+		kind[x.Var()] = k
+		return nil
+	}
+	for _, x := range g.Inputs {
+		err := define(x, input)
+		if err != nil {
+			return err
+		}
+	}
+	for _, l := range g.Latches {
+		err := define(l.Lit, latch)
+		if err != nil {
+			return err
+		}
+	}
+	for i, a := range g.Ands {
+		err := define(a.Lit, and)
+		if err != nil {
+			return err
+		}
+		gate[a.Lit.Var()] = i
+	}
+
+	var used []Lit
+	for _, l := range g.Latches {
+		used = append(used, l.Next)
+	}
+	used = append(used, g.Outputs...)
+	used = append(used, g.Bad...)
+	used = append(used, g.Constraints...)
+	for _, a := range g.Ands {
+		used = append(used, a.In0, a.In1)
+	}
+	for _, x := range used {
+		if x.Var() != 0 && kind[x.Var()] == undef {
+			return fmt.Errorf("aiger: literal %d is not defined", x)
+		}
+	}
+
+	// depth first search for cycles through AND gates
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(g.Ands))
+	var stack []int
+	for i := range g.Ands {
+		if state[i] != unvisited {
+			continue
+		}
+		stack = append(stack[:0], i)
+		for len(stack) > 0 {
+			j := stack[len(stack)-1]
+			if state[j] == visiting {
+				state[j] = visited
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			if state[j] == visited {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			state[j] = visiting
+			for _, x := range []Lit{g.Ands[j].In0, g.Ands[j].In1} {
+				if kind[x.Var()] != and {
+					continue
+				}
+				k := gate[x.Var()]
+				if state[k] == visiting {
+					return fmt.Errorf("aiger: cycle through literal %d", g.Ands[k].Lit)
+				}
+				if state[k] == unvisited {
+					stack = append(stack, k)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package aiger
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bmatsuo/dpll"
+)
+
+// counter is a two bit counter which is incremented when its input is true.
+// The bad state is reached when both bits are set.
+const counter = `aag 10 1 2 0 7 1
+2
+4 12
+6 18
+20
+8 4 2
+10 5 3
+12 11 9
+14 8 6
+16 9 7
+18 17 15
+20 6 4
+i0 enable
+l0 bit0
+c
+a comment
+`
+
+// counterBinary is counter in the binary format.
+var counterBinary = "aig 10 1 2 0 7 1\n12\n18\n20\n" +
+	"\x04\x02" + "\x05\x02" + "\x01\x02" + "\x06\x02" + "\x07\x02" + "\x01\x02" + "\x0e\x02" +
+	"i0 enable\n"
+
+func TestDecode(t *testing.T) {
+	g, err := Decode(strings.NewReader(counter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &AIG{
+		MaxVar:  10,
+		Inputs:  []Lit{2},
+		Latches: []Latch{{4, 12, 0}, {6, 18, 0}},
+		Bad:     []Lit{20},
+		Ands: []And{
+			{8, 4, 2}, {10, 5, 3}, {12, 11, 9},
+			{14, 8, 6}, {16, 9, 7}, {18, 17, 15},
+			{20, 6, 4},
+		},
+	}
+	if !reflect.DeepEqual(g, expect) {
+		t.Errorf("decoded %+v (!= %+v)", g, expect)
+	}
+
+	g2, err := Decode(strings.NewReader(counterBinary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g2, expect) {
+		t.Errorf("decoded %+v (!= %+v)", g2, expect)
+	}
+}
+
+func TestDecode_error(t *testing.T) {
+	tests := []string{
+		"",
+		"aag 1 0 0 0\n",
+		"aag 1 1 0 0 0\n",
+		"aag 1 1 0 0 0\n3\n",
+		"aag 1 1 0 0 0\n4\n",
+		"aag 2 2 0 0 0\n2\n2\n",
+		"aag 1 0 1 0 0\n2 3 3\n",
+		"aag 1 0 0 1 0\n2\n",
+		"aag 2 0 0 0 2\n2 4 1\n4 2 1\n",
+		"aag 1 1 0 0 0 0 0 1\n2\n",
+		"aig 2 1 0 0 1\n\x02",
+		"aig 2 1 0 0 1\n\x05\x00",
+		"aig 2 1 0 0 2\n",
+	}
+	for i, in := range tests {
+		_, err := Decode(strings.NewReader(in))
+		if err == nil {
+			t.Errorf("test %d: no error", i)
+		}
+	}
+}
+
+// simulate returns true if w reaches the bad state of g in its last frame
+// without violating a constraint.  Undefined values are taken as false.
+func simulate(g *AIG, w *Witness) bool {
+	val := make([]bool, g.MaxVar+1)
+	get := func(x Lit) bool {
+		return val[x.Var()] != x.IsNeg()
+	}
+	for i, l := range g.Latches {
+		val[l.Lit.Var()] = w.Latches[i].IsTrue()
+	}
+	for k, inputs := range w.Inputs {
+		for i, x := range g.Inputs {
+			val[x.Var()] = inputs[i].IsTrue()
+		}
+		for _, a := range g.Ands {
+			val[a.Lit.Var()] = get(a.In0) && get(a.In1)
+		}
+		for _, x := range g.Constraints {
+			if !get(x) {
+				return false
+			}
+		}
+		if k == len(w.Inputs)-1 {
+			return get(g.Bad[w.Bad])
+		}
+		next := make([]bool, len(g.Latches))
+		for i, l := range g.Latches {
+			next[i] = get(l.Next)
+		}
+		for i, l := range g.Latches {
+			val[l.Lit.Var()] = next[i]
+		}
+	}
+	return false
+}
+
+func TestBMC(t *testing.T) {
+	g, err := Decode(strings.NewReader(counter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []Solver{dpll.New(nil), dpll.NewSimp(nil, nil)} {
+		b := NewBMC(s, g, 0)
+		status := b.Run(10)
+		if !status.IsTrue() {
+			t.Fatalf("%T: %v", s, status)
+		}
+		if b.Bound() != 3 {
+			t.Errorf("%T: bound %d", s, b.Bound())
+		}
+		w := b.Witness()
+		if len(w.Inputs) != 4 {
+			t.Errorf("%T: witness has %d frames", s, len(w.Inputs))
+		}
+		if !simulate(g, w) {
+			t.Errorf("%T: witness does not reach the bad state: %v", s, w)
+		}
+	}
+}
+
+func TestBMC_unreachable(t *testing.T) {
+	// the counter is not incremented when its input is constrained false
+	in := strings.Replace(counter, "aag 10 1 2 0 7 1\n", "aag 10 1 2 0 7 1 1\n", 1)
+	in = strings.Replace(in, "20\n8 4 2\n", "20\n3\n8 4 2\n", 1)
+	g, err := Decode(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.Constraints, []Lit{3}) {
+		t.Fatalf("constraints %v", g.Constraints)
+	}
+	b := NewBMC(dpll.New(nil), g, 0)
+	status := b.Run(6)
+	if !status.IsFalse() || b.Bound() != 6 || b.Witness() != nil {
+		t.Errorf("%v bound %d", status, b.Bound())
+	}
+}
+
+func TestBMC_uninitialized(t *testing.T) {
+	g, err := Decode(strings.NewReader("aag 1 0 1 0 0 1\n2 2 2\n2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewBMC(dpll.New(nil), g, 0)
+	if !b.Run(3).IsTrue() {
+		t.Fatalf("bad state not reached")
+	}
+	var buf bytes.Buffer
+	err = EncodeWitness(&buf, b.Witness())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1\nb0\n1\n\n.\n" {
+		t.Errorf("witness %q", buf.String())
+	}
+}
+
+func TestEncodeWitness(t *testing.T) {
+	w := &Witness{
+		Bad:     1,
+		Latches: []dpll.LBool{dpll.LFalse, dpll.LTrue},
+		Inputs: [][]dpll.LBool{
+			{dpll.LTrue, dpll.LUndef},
+			{dpll.LFalse, dpll.LTrue},
+		},
+	}
+	var buf bytes.Buffer
+	err := EncodeWitness(&buf, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := "1\nb1\n01\n1x\n01\n.\n"
+	if buf.String() != out {
+		t.Errorf("output %q (!= %q)", buf.String(), out)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package aiger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/bmatsuo/dpll"
+)
+
+// Solver is a dpll.Solver which reports models.  DPLL and Simp implement
+// Solver.
+type Solver interface {
+	dpll.Solver
+	Model() []dpll.LBool
+}
+
+// BMC is a bounded model checker for a bad-state property of an AIG.  The
+// transition relation is unrolled one frame at a time in a single solver.
+// Each frame is checked by solving under the assumption that the bad state
+// literal of the frame is true.  When the bad state is unreachable in a frame
+// its negation is added to the solver, which helps later frames.
+type BMC struct {
+	s       Solver
+	g       *AIG
+	e       *Encoder
+	prop    int
+	init    []dpll.Lit
+	frames  []*Frame
+	bound   int // number of frames in which the bad state is unreachable
+	witness *Witness
+}
+
+// NewBMC returns a BMC checking the bad-state property g.Bad[prop] using s.
+func NewBMC(s Solver, g *AIG, prop int) *BMC {
+	if prop < 0 || prop >= len(g.Bad) {
+		panic("property is not a bad state of the graph")
+	}
+	b := &BMC{s: s, g: g, prop: prop}
+	b.e = NewEncoder(s, g)
+	b.init = b.e.InitialState()
+	return b
+}
+
+// Bound returns the number of time frames in which the bad state has been
+// shown to be unreachable.
+func (b *BMC) Bound() int {
+	return b.bound
+}
+
+// Witness returns the counterexample found by Step or Run, or nil if the bad
+// state has not been reached.
+func (b *BMC) Witness() *Witness {
+	return b.witness
+}
+
+// Step checks if the bad state is reachable in the first time frame not yet
+// checked, adding the frame to the solver if necessary.  Step returns LTrue if
+// the bad state is reachable, in which case Witness returns the
+// counterexample.  Step returns LFalse if it is not, and LUndef if the search
+// was interrupted or exceeded a budget set on the solver, in which case the
+// frame may be checked again by calling Step.
+func (b *BMC) Step() dpll.LBool {
+	if b.witness != nil {
+		return dpll.LTrue
+	}
+	k := b.bound
+	if k == len(b.frames) {
+		state := b.init
+		if k > 0 {
+			state = b.frames[k-1].Next()
+		}
+		b.frames = append(b.frames, b.e.Encode(state))
+	}
+	bad := b.frames[k].Lit(b.g.Bad[b.prop])
+	status := b.s.SolveLimited(bad)
+	switch {
+	case status.IsTrue():
+		b.witness = b.newWitness(b.s.Model())
+	case status.IsFalse():
+		b.s.AddClause(bad.Inverse())
+		b.bound++
+	}
+	return status
+}
+
+// Run calls Step until the bad state is reached, it is shown unreachable in
+// the first maxFrames frames, or the search is interrupted.
+func (b *BMC) Run(maxFrames int) dpll.LBool {
+	for b.witness == nil && b.bound < maxFrames {
+		status := b.Step()
+		if status.IsUndef() {
+			return status
+		}
+	}
+	if b.witness != nil {
+		return dpll.LTrue
+	}
+	return dpll.LFalse
+}
+
+func (b *BMC) newWitness(model []dpll.LBool) *Witness {
+	value := func(ps []dpll.Lit) []dpll.LBool {
+		vals := make([]dpll.LBool, len(ps))
+		for i, p := range ps {
+			vals[i] = dpll.LUndef
+			if int(p.Var()) < len(model) {
+				vals[i] = model[p.Var()].Xor(p.IsNeg())
+			}
+		}
+		return vals
+	}
+	w := &Witness{Bad: b.prop, Latches: value(b.init)}
+	for _, f := range b.frames {
+		w.Inputs = append(w.Inputs, value(f.Inputs()))
+	}
+	return w
+}
+
+// Witness is a counterexample to a bad-state property.  The bad state is
+// reached in the last frame when the latches begin with the given values and
+// the inputs take the given values in each frame.  Values may be LUndef if
+// they are irrelevant.
+type Witness struct {
+	Bad     int            // index of the property in AIG.Bad
+	Latches []dpll.LBool   // initial values of the latches
+	Inputs  [][]dpll.LBool // values of the inputs in each frame
+}
+
+// EncodeWitness writes wit in the AIGER witness format.  Undefined values are
+// written as 'x'.
+//
+//		1
+//		b0
+//		00
+//		1x
+//		01
+//		.
+func EncodeWitness(w io.Writer, wit *Witness) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "1\nb%d\n", wit.Bad)
+	writeValues(bw, wit.Latches)
+	for _, vals := range wit.Inputs {
+		writeValues(bw, vals)
+	}
+	bw.WriteString(".\n")
+	return bw.Flush()
+}
+
+func writeValues(bw *bufio.Writer, vals []dpll.LBool) {
+	for _, x := range vals {
+		switch {
+		case x.IsTrue():
+			bw.WriteByte('1')
+		case x.IsFalse():
+			bw.WriteByte('0')
+		default:
+			bw.WriteByte('x')
+		}
+	}
+	bw.WriteByte('\n')
+}
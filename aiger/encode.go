@@ -0,0 +1,127 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package aiger
+
+import "github.com/bmatsuo/dpll"
+
+// Encoder adds copies of the combinational logic of an AIG to a solver.
+type Encoder struct {
+	s    dpll.Solver
+	g    *AIG
+	zero dpll.Lit
+}
+
+// NewEncoder returns an Encoder adding clauses to s.  NewEncoder creates a
+// variable in s which is constrained to be false.
+func NewEncoder(s dpll.Solver, g *AIG) *Encoder {
+	e := &Encoder{s: s, g: g}
+	e.zero = dpll.Literal(s.NewVar(dpll.LFalse, false), false)
+	s.AddClause(e.zero.Inverse())
+	return e
+}
+
+// Lit returns a solver literal with the constant value of x, which must be
+// False or True.
+func (e *Encoder) Lit(x Lit) dpll.Lit {
+	if x.Var() != 0 {
+		panic("literal is not constant")
+	}
+	return e.zero.Xor(x.IsNeg())
+}
+
+// InitialState returns the solver literals of the initial values of the
+// latches.  Uninitialized latches are given new variables.
+func (e *Encoder) InitialState() []dpll.Lit {
+	state := make([]dpll.Lit, len(e.g.Latches))
+	for i, l := range e.g.Latches {
+		if l.Init == l.Lit {
+			state[i] = dpll.Literal(e.s.NewVar(dpll.LUndef, true), false)
+		} else {
+			state[i] = e.Lit(l.Init)
+		}
+	}
+	return state
+}
+
+// Encode adds a copy of the combinational logic to the solver, in which the
+// latches take the values of state, and returns it as a Frame.  Each input
+// and AND gate is given a new variable and each gate is Tseitin encoded.  If
+// state is nil the latches are given new variables.
+func (e *Encoder) Encode(state []dpll.Lit) *Frame {
+	if state != nil && len(state) != len(e.g.Latches) {
+		panic("state does not match the latches")
+	}
+	f := &Frame{g: e.g, lits: make([]dpll.Lit, e.g.MaxVar+1)}
+	f.lits[0] = e.zero
+	for _, x := range e.g.Inputs {
+		f.lits[x.Var()] = dpll.Literal(e.s.NewVar(dpll.LUndef, true), false)
+	}
+	for i, l := range e.g.Latches {
+		if state == nil {
+			f.lits[l.Lit.Var()] = dpll.Literal(e.s.NewVar(dpll.LUndef, true), false)
+		} else {
+			f.lits[l.Lit.Var()] = state[i]
+		}
+	}
+	for _, a := range e.g.Ands {
+		f.lits[a.Lit.Var()] = dpll.Literal(e.s.NewVar(dpll.LUndef, true), false)
+	}
+	for _, a := range e.g.Ands {
+		p, q, r := f.Lit(a.Lit), f.Lit(a.In0), f.Lit(a.In1)
+		e.s.AddClause(p.Inverse(), q)
+		e.s.AddClause(p.Inverse(), r)
+		e.s.AddClause(p, q.Inverse(), r.Inverse())
+	}
+	for _, x := range e.g.Constraints {
+		e.s.AddClause(f.Lit(x))
+	}
+	return f
+}
+
+// Frame is a copy of the combinational logic of an AIG in a solver, one time
+// frame of the transition relation.  The invariant constraints of the graph
+// hold in every frame.
+type Frame struct {
+	g    *AIG
+	lits []dpll.Lit // solver literal of each variable
+}
+
+// Lit returns the solver literal of x in f.
+func (f *Frame) Lit(x Lit) dpll.Lit {
+	return f.lits[x.Var()].Xor(x.IsNeg())
+}
+
+// Inputs returns the solver literals of the inputs in f.
+func (f *Frame) Inputs() []dpll.Lit {
+	return f.lookup(f.g.Inputs)
+}
+
+// State returns the solver literals of the latches in f.
+func (f *Frame) State() []dpll.Lit {
+	xs := make([]Lit, len(f.g.Latches))
+	for i, l := range f.g.Latches {
+		xs[i] = l.Lit
+	}
+	return f.lookup(xs)
+}
+
+// Next returns the solver literals of the next values of the latches in f,
+// which are the state of the following frame.
+func (f *Frame) Next() []dpll.Lit {
+	xs := make([]Lit, len(f.g.Latches))
+	for i, l := range f.g.Latches {
+		xs[i] = l.Next
+	}
+	return f.lookup(xs)
+}
+
+func (f *Frame) lookup(xs []Lit) []dpll.Lit {
+	ps := make([]dpll.Lit, len(xs))
+	for i, x := range xs {
+		ps[i] = f.Lit(x)
+	}
+	return ps
+}
@@ -0,0 +1,149 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+// backboneSolver is the part of DPLL and Simp used to compute backbones.
+type backboneSolver interface {
+	SolveLimited(assump ...Lit) LBool
+	Model() []LBool
+	NewGroup() Group
+	AddClauseToGroup(g Group, ps ...Lit) bool
+	DeleteGroup(g Group)
+}
+
+// Backbone returns the literals of candidates which are true in every model
+// of the problem.  Each candidate is tested with a call to SolveLimited
+// assuming its negation, unless propagation alone shows the negation to be
+// inconsistent.  Models found along the way remove candidates which take
+// both values.
+//
+// The status returned is LTrue if every candidate was decided and LFalse if
+// the problem is unsatisfiable.  If a budget is exhausted or the search is
+// interrupted the status is LUndef and the backbone literals found so far are
+// returned.
+func (d *DPLL) Backbone(candidates []Var) (backbone []Lit, status LBool) {
+	return d.backbone(d, candidates, 1)
+}
+
+// BackboneChunked behaves like Backbone but tests up to chunk candidates with
+// each call to SolveLimited, assuming that at least one of them takes the
+// value opposite the one in the last model found.  If the problem is
+// unsatisfiable under the assumption all candidates in the chunk are part of
+// the backbone.
+func (d *DPLL) BackboneChunked(candidates []Var, chunk int) (backbone []Lit, status LBool) {
+	return d.backbone(d, candidates, chunk)
+}
+
+// Backbone behaves like DPLL.Backbone.  Candidates may not be eliminated and
+// are frozen until Backbone returns.
+func (s *Simp) Backbone(candidates []Var) (backbone []Lit, status LBool) {
+	return s.BackboneChunked(candidates, 1)
+}
+
+// BackboneChunked behaves like DPLL.BackboneChunked.  Candidates may not be
+// eliminated and are frozen until BackboneChunked returns.
+func (s *Simp) BackboneChunked(candidates []Var, chunk int) (backbone []Lit, status LBool) {
+	var extraFrozen []Var
+	for _, v := range candidates {
+		if s.IsEliminated(v) {
+			panic("candidate is an eliminated variable")
+		}
+		if !s.frozen[v] {
+			s.SetFrozen(v, true)
+			extraFrozen = append(extraFrozen, v)
+		}
+	}
+	defer func() {
+		for _, v := range extraFrozen {
+			s.SetFrozen(v, false)
+		}
+	}()
+	return s.d.backbone(s, candidates, chunk)
+}
+
+func (d *DPLL) backbone(s backboneSolver, candidates []Var, chunk int) (backbone []Lit, status LBool) {
+	if chunk < 1 {
+		panic("chunk size must be positive")
+	}
+
+	status = s.SolveLimited()
+	if !status.IsTrue() {
+		return nil, status
+	}
+
+	// lits holds the value of each remaining candidate in the last model
+	var lits []Lit
+	model := s.Model()
+	for _, v := range candidates {
+		if model[v].IsUndef() {
+			// the variable is not constrained
+			continue
+		}
+		p := Literal(v, model[v].IsFalse())
+		if d.ValueLit(p).IsTrue() {
+			backbone = append(backbone, p)
+		} else {
+			lits = append(lits, p)
+		}
+	}
+
+	for len(lits) > 0 {
+		// propagation alone may show that the negation of a candidate fails
+		var test []Lit
+		for len(lits) > 0 && len(test) < chunk {
+			p := lits[len(lits)-1]
+			lits = lits[:len(lits)-1]
+			assump := append(d.groupAssumptions(backbone), p.Inverse())
+			if _, ok := d.Implies(assump); ok {
+				test = append(test, p)
+			} else {
+				backbone = append(backbone, p)
+			}
+		}
+		if len(test) == 0 {
+			continue
+		}
+
+		switch len(test) {
+		case 1:
+			status = s.SolveLimited(append(backbone, test[0].Inverse())...)
+		default:
+			g := s.NewGroup()
+			neg := make([]Lit, len(test))
+			for i, p := range test {
+				neg[i] = p.Inverse()
+			}
+			s.AddClauseToGroup(g, neg...)
+			status = s.SolveLimited(backbone...)
+			s.DeleteGroup(g)
+		}
+
+		switch {
+		case status.IsFalse():
+			backbone = append(backbone, test...)
+		case status.IsTrue():
+			// candidates taking other values in the model are not backbone
+			model = s.Model()
+			lits = filterModel(append(lits, test...), model)
+		default:
+			return backbone, LUndef
+		}
+	}
+
+	return backbone, LTrue
+}
+
+// filterModel returns the literals in ps which are true in model, reusing the
+// storage of ps.
+func filterModel(ps []Lit, model []LBool) []Lit {
+	qs := ps[:0]
+	for _, p := range ps {
+		if model[p.Var()].Xor(p.IsNeg()).IsTrue() {
+			qs = append(qs, p)
+		}
+	}
+	return qs
+}
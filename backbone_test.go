@@ -0,0 +1,104 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"sort"
+	"testing"
+)
+
+// bruteBackbone computes the backbone of the variables 1..n by solving with
+// each literal assumed false.
+func bruteBackbone(s Solver, n int) []Lit {
+	var backbone []Lit
+	for v := 1; v <= n; v++ {
+		for _, p := range []Lit{LiteralInt(v), LiteralInt(-v)} {
+			if !s.Solve(p.Inverse()) {
+				backbone = append(backbone, p)
+			}
+		}
+	}
+	return backbone
+}
+
+type backboneFunc func(candidates []Var) ([]Lit, LBool)
+
+func TestDPLL_Backbone(t *testing.T) {
+	for seed := int64(1); seed < 40; seed++ {
+		p := randomProblem(seed, 30, 120)
+		check := New(nil)
+		addProblem(check, p)
+		if !check.Solve() {
+			continue
+		}
+		want := bruteBackbone(check, p.NumVar)
+		sort.Sort(litSlice(want))
+
+		var candidates []Var
+		for v := 1; v <= p.NumVar; v++ {
+			candidates = append(candidates, Var(v))
+		}
+
+		d := New(nil)
+		addProblem(d, p)
+		s := NewSimp(nil, nil)
+		addProblem(s, p)
+		fns := map[string]backboneFunc{
+			"dpll":    d.Backbone,
+			"simp":    s.Backbone,
+			"chunked": func(vs []Var) ([]Lit, LBool) { return d.BackboneChunked(vs, 4) },
+		}
+		for name, fn := range fns {
+			backbone, status := fn(candidates)
+			if !status.IsTrue() {
+				t.Errorf("seed %d %s: status %v", seed, name, status)
+				continue
+			}
+			sort.Sort(litSlice(backbone))
+			if !litsEqual(backbone, want) {
+				t.Errorf("seed %d %s: backbone %v (!= %v)", seed, name, backbone, want)
+			}
+		}
+	}
+}
+
+func TestDPLL_Backbone_unsat(t *testing.T) {
+	d := New(nil)
+	d.NewVar(LUndef, true)
+	d.AddClause(LiteralInt(1))
+	d.AddClause(LiteralInt(-1))
+	backbone, status := d.Backbone([]Var{1})
+	if !status.IsFalse() || len(backbone) != 0 {
+		t.Errorf("backbone %v status %v", backbone, status)
+	}
+}
+
+func TestDPLL_Backbone_budget(t *testing.T) {
+	p := randomProblem(3, 200, 820)
+	d := New(nil)
+	addProblem(d, p)
+	var candidates []Var
+	for v := 1; v <= p.NumVar; v++ {
+		candidates = append(candidates, Var(v))
+	}
+	d.SetConflictBudget(1)
+	_, status := d.Backbone(candidates)
+	if !status.IsUndef() {
+		t.Errorf("status %v", status)
+	}
+}
+
+func litsEqual(a, b []Lit) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
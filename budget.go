@@ -0,0 +1,138 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"time"
+)
+
+// Limit is a reason for SolveLimited to stop before the search completes.
+type Limit int
+
+// Available Limit values.
+const (
+	NoLimit Limit = iota
+	LimitInterrupt
+	LimitConflicts
+	LimitPropagations
+	LimitTime
+	LimitMemory
+)
+
+var limitStrings = []string{
+	NoLimit:           "none",
+	LimitInterrupt:    "interrupt",
+	LimitConflicts:    "conflict budget",
+	LimitPropagations: "propagation budget",
+	LimitTime:         "time budget",
+	LimitMemory:       "memory budget",
+}
+
+func (l Limit) String() string {
+	if l >= 0 && int(l) < len(limitStrings) {
+		return limitStrings[l]
+	}
+	return fmt.Sprintf("Limit(%d)", int(l))
+}
+
+// clockInterval is the number of calls to timeExceeded between readings of
+// the clock.  The budget is checked for every decision, which is too often to
+// read the clock each time.
+const clockInterval = 256
+
+// memCheckInterval is the number of conflicts between measurements of the
+// heap, which stop the world.
+const memCheckInterval = 1000
+
+// SetTimeBudget limits calls to SolveLimited, and simplification by a Simp,
+// to the wall-clock duration dur from now.  The budget is checked
+// periodically, so the search may run slightly longer.
+func (d *DPLL) SetTimeBudget(dur time.Duration) {
+	d.deadline = time.Now().Add(dur)
+	d.timedOut = false
+	d.nclockChecks = 0
+}
+
+// SetMemoryBudget limits the heap used while SolveLimited runs to n bytes.
+// The heap is measured every thousand conflicts.  When it exceeds n the
+// learnt clauses are reduced, clause memory is collected and the Go garbage
+// collector is run.  The search stops only if the heap still exceeds n
+// afterward.  The budget covers the heap of the whole process.
+func (d *DPLL) SetMemoryBudget(n uint64) {
+	d.memoryBudget = n
+	d.memExceeded = false
+	d.nextMemCheck = d.nconflicts
+}
+
+// timeExceeded returns true if the time budget has run out.
+func (d *DPLL) timeExceeded() bool {
+	if d.deadline.IsZero() || d.timedOut {
+		return d.timedOut
+	}
+	d.nclockChecks++
+	if d.nclockChecks%clockInterval == 1 {
+		d.timedOut = !time.Now().Before(d.deadline)
+	}
+	return d.timedOut
+}
+
+// checkMemory measures the heap.  If it exceeds the memory budget the learnt
+// clauses are reduced, their limit is lowered, and memory is collected
+// before the heap is measured again.  If the heap is still too large the
+// budget is exhausted.
+func (d *DPLL) checkMemory() {
+	d.nextMemCheck = d.nconflicts + memCheckInterval
+	if heapAlloc() <= d.memoryBudget {
+		return
+	}
+	d.nmemReduce++
+	if d.Verbosity >= 1 {
+		log.Printf("|  Memory budget exceeded: reducing %d learnt clauses                         |", len(d.learnt))
+	}
+	d.reduceDB()
+	d.maxLearnt = float64(len(d.learnt))
+	if d.maxLearnt < float64(d.MinLearnt) {
+		d.maxLearnt = float64(d.MinLearnt)
+	}
+	d.checkGarbageFrac(0, true)
+	runtime.GC()
+	if heapAlloc() > d.memoryBudget {
+		d.memExceeded = true
+	}
+}
+
+func heapAlloc() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// Limit returns the first budget found to be exhausted, or NoLimit.  When
+// SolveLimited returns LUndef, Limit reports why the search stopped.
+func (d *DPLL) Limit() Limit {
+	switch {
+	case d.wasInterrupted():
+		return LimitInterrupt
+	case d.conflictBudget >= 0 && d.nconflicts >= uint64(d.conflictBudget):
+		return LimitConflicts
+	case d.propagationBudget >= 0 && d.npropogations >= uint64(d.propagationBudget):
+		return LimitPropagations
+	case d.timedOut:
+		return LimitTime
+	case d.memExceeded:
+		return LimitMemory
+	}
+	return NoLimit
+}
+
+// Progress returns an estimate of the fraction of the search space explored,
+// between 0 and 1, when SolveLimited last stopped on a budget.
+func (d *DPLL) Progress() float64 {
+	return d.progress
+}
@@ -0,0 +1,117 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// pigeonhole returns a problem placing n+1 pigeons in n holes, which is hard
+// for resolution.
+func pigeonhole(n int) *dimacs.Problem {
+	pigeon := func(i, j int) dimacs.Lit { return dimacs.Lit(i*n + j + 1) }
+	p := &dimacs.Problem{NumVar: (n + 1) * n}
+	for i := 0; i <= n; i++ {
+		var c []dimacs.Lit
+		for j := 0; j < n; j++ {
+			c = append(c, pigeon(i, j))
+		}
+		p.Clauses = append(p.Clauses, c)
+	}
+	for j := 0; j < n; j++ {
+		for i := 0; i <= n; i++ {
+			for k := i + 1; k <= n; k++ {
+				p.Clauses = append(p.Clauses, []dimacs.Lit{-pigeon(i, j), -pigeon(k, j)})
+			}
+		}
+	}
+	return p
+}
+
+func TestDPLL_SetTimeBudget(t *testing.T) {
+	d := New(nil)
+	addProblem(d, pigeonhole(11))
+	d.SetTimeBudget(50 * time.Millisecond)
+	start := time.Now()
+	status := d.SolveLimited()
+	if !status.IsUndef() {
+		t.Fatalf("status %v", status)
+	}
+	if dur := time.Since(start); dur > 2*time.Second {
+		t.Errorf("search stopped after %v", dur)
+	}
+	if d.Limit() != LimitTime {
+		t.Errorf("limit %v", d.Limit())
+	}
+	if p := d.Progress(); p <= 0 || p > 1 {
+		t.Errorf("progress %g", p)
+	}
+
+	// the budget is absolute, so the next search stops immediately.
+	nconflicts := d.Stats().Conflicts
+	if status := d.SolveLimited(); !status.IsUndef() {
+		t.Errorf("status %v", status)
+	}
+	if n := d.Stats().Conflicts - nconflicts; n > 2*clockInterval {
+		t.Errorf("%d conflicts after the time budget", n)
+	}
+
+	d.BudgetOff()
+	if d.Limit() != NoLimit {
+		t.Errorf("limit %v", d.Limit())
+	}
+}
+
+func TestDPLL_Progress_restart(t *testing.T) {
+	d := New(&Opt{RestartFirst: 10})
+	addProblem(d, pigeonhole(6))
+	if d.Solve() {
+		t.Fatalf("satisfiable")
+	}
+	if d.Stats().Restarts < 2 {
+		t.Skipf("%d restarts", d.Stats().Restarts)
+	}
+	if p := d.Progress(); p != 0 {
+		t.Errorf("progress %g after restarts", p)
+	}
+}
+
+func TestDPLL_SetMemoryBudget(t *testing.T) {
+	d := New(nil)
+	addProblem(d, pigeonhole(11))
+	d.SetConflictBudget(100000)
+	d.SetMemoryBudget(1)
+	if status := d.SolveLimited(); !status.IsUndef() {
+		t.Fatalf("status %v", status)
+	}
+	if d.Limit() != LimitMemory {
+		t.Errorf("limit %v", d.Limit())
+	}
+	if d.Stats().MemReductions == 0 {
+		t.Errorf("learnt clauses were not reduced")
+	}
+}
+
+func TestSimp_SetTimeBudget(t *testing.T) {
+	s := NewSimp(nil, nil)
+	addProblem(s, pigeonhole(8))
+	s.SetTimeBudget(-time.Second)
+	if !s.Eliminate(false) {
+		t.Fatalf("simplification failed")
+	}
+	if s.Stats().EliminatedVars != 0 {
+		t.Errorf("%d variables eliminated after the time budget", s.Stats().EliminatedVars)
+	}
+	if status := s.SolveLimited(); !status.IsUndef() {
+		t.Errorf("status %v", status)
+	}
+	if s.Limit() != LimitTime {
+		t.Errorf("limit %v", s.Limit())
+	}
+}
@@ -110,6 +110,8 @@ func (c *Clause) Len() int {
 type ClauseExtra struct {
 	Activity    float64
 	Abstraction uint32
+	LBD         uint32 // number of distinct decision levels when learnt
+	Vivified    bool   // the clause has already been vivified
 }
 
 // Subsumes checks if c subsumes c2 and if it can be used to simplify c2 by
@@ -0,0 +1,129 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+// Clone returns a copy of d which shares no state with d.  The copy has the
+// same variables, clauses, learnt clauses, watches, decision order and random
+// number generator state, so the two solvers behave identically until they
+// are given different input.  Options are copied, including any functions
+// they hold.  A connected Propagator is not shared and must be connected to
+// the copy separately.  The copy is not interrupted.
+func (d *DPLL) Clone() *DPLL {
+	return d.clone(make(clauseMap))
+}
+
+// Clone behaves like DPLL.Clone.  The copy also has the simplification state
+// of s, including the clauses needed to extend models to eliminated
+// variables.
+func (s *Simp) Clone() *Simp {
+	m := make(clauseMap)
+	s2 := &Simp{}
+	*s2 = *s
+	s2.d = s.d.clone(m)
+	s2.d.addClauseFn = s2.addClause
+	s2.d.removeClauseFn = s2.removeClause
+	s2.d.garbageCollectFn = s2.garbageCollect
+	s2.d.inprocessFn = s2.inprocess
+
+	s2.bwdsubTmpUnit = m.get(s.bwdsubTmpUnit)
+	if s.subQueue != nil {
+		s2.subQueue = s.subQueue.clone(m.get)
+	}
+	s2.numOcc = append([]int(nil), s.numOcc...)
+	if s.elimHeap != nil {
+		s2.elimHeap = s.elimHeap.clone(&s2.numOcc)
+	}
+	if s.occurs != nil {
+		s2.occurs = s.occurs.clone(m.get)
+	}
+	s2.touched = append([]bool(nil), s.touched...)
+	s2.eliminated = append([]bool(nil), s.eliminated...)
+	s2.frozen = append([]bool(nil), s.frozen...)
+	s2.frozenVars = append([]Var(nil), s.frozenVars...)
+	s2.elimClauses = append([]uint32(nil), s.elimClauses...)
+	return s2
+}
+
+func (d *DPLL) clone(m clauseMap) *DPLL {
+	d2 := &DPLL{}
+	*d2 = *d
+	d2.asyncInterrupt = 0
+	d2.prop = nil
+
+	d2.model = append([]LBool(nil), d.model...)
+	d2.conflict = append([]Lit(nil), d.conflict...)
+	d2.conflictGroups = append([]Group(nil), d.conflictGroups...)
+
+	d2.clauses = m.getAll(d.clauses)
+	d2.learnt = m.getAll(d.learnt)
+	d2.trail = append([]Lit(nil), d.trail...)
+	d2.trailLim = append([]int(nil), d.trailLim...)
+	d2.assumptions = append([]Lit(nil), d.assumptions...)
+
+	d2.observed = append([]bool(nil), d.observed...)
+	d2.groups = append([]clauseGroup(nil), d.groups...)
+	if d.groupOf != nil {
+		d2.groupOf = make(map[Var]Group, len(d.groupOf))
+		for v, g := range d.groupOf {
+			d2.groupOf[v] = g
+		}
+	}
+
+	d2.activity = append([]float64(nil), d.activity...)
+	d2.assigns = append([]LBool(nil), d.assigns...)
+	d2.polarity = append([]bool(nil), d.polarity...)
+	d2.upolarity = append([]LBool(nil), d.upolarity...)
+	d2.decision = append([]bool(nil), d.decision...)
+	d2.vardata = make([]varData, len(d.vardata))
+	for v, vd := range d.vardata {
+		d2.vardata[v] = varData{m.get(vd.Reason), vd.Level}
+	}
+	d2.watches = d.watches.clone(m.get)
+	d2.orderHeap = d.orderHeap.clone(&d2.activity)
+	d2.releasedVars = append([]Var(nil), d.releasedVars...)
+	d2.freeVars = append([]Var(nil), d.freeVars...)
+	if d.original != nil {
+		d2.original = make([][]Lit, len(d.original))
+		for i, ps := range d.original {
+			d2.original[i] = append([]Lit(nil), ps...)
+		}
+	}
+
+	d2.seen = append([]Seen(nil), d.seen...)
+	d2.analyzeStack = append([]shrinkLit(nil), d.analyzeStack...)
+	d2.analyzeToClear = append([]Lit(nil), d.analyzeToClear...)
+	d2.addTmp = append([]Lit(nil), d.addTmp...)
+	d2.lbdSeen = append([]uint32(nil), d.lbdSeen...)
+
+	return d2
+}
+
+// clauseMap maps the clauses of a solver to their copies in a clone.
+type clauseMap map[*Clause]*Clause
+
+// get returns the copy of c, creating it if necessary.
+func (m clauseMap) get(c *Clause) *Clause {
+	if c == nil {
+		return nil
+	}
+	c2, ok := m[c]
+	if !ok {
+		c2 = NewClauseFrom(c, c.ClauseExtra != nil)
+		m[c] = c2
+	}
+	return c2
+}
+
+func (m clauseMap) getAll(cs []*Clause) []*Clause {
+	if cs == nil {
+		return nil
+	}
+	cs2 := make([]*Clause, len(cs))
+	for i, c := range cs {
+		cs2[i] = m.get(c)
+	}
+	return cs2
+}
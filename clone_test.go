@@ -0,0 +1,105 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import "testing"
+
+func TestDPLL_Clone(t *testing.T) {
+	for seed := int64(1); seed < 10; seed++ {
+		p := randomProblem(seed, 80, 340)
+		d := New(&Opt{RandVarFreq: 0.1})
+		addProblem(d, p)
+		d.SetConflictBudget(100)
+		d.SolveLimited()
+
+		// the copy searches exactly as the original does
+		c := d.Clone()
+		d.SetConflictBudget(2000)
+		c.SetConflictBudget(2000)
+		status := d.SolveLimited()
+		cstatus := c.SolveLimited()
+		if cstatus != status || c.nconflicts != d.nconflicts || c.ndecisions != d.ndecisions {
+			t.Errorf("seed %d: clone %v after %d conflicts (expected %v after %d)", seed, cstatus, c.nconflicts, status, d.nconflicts)
+		}
+		if status.IsTrue() {
+			m, cm := d.Model(), c.Model()
+			for v := 1; v <= p.NumVar; v++ {
+				if m[v] != cm[v] {
+					t.Errorf("seed %d: models differ at %d", seed, v)
+					break
+				}
+			}
+		}
+
+		// constraints added to the copy do not affect the original
+		c = d.Clone()
+		c.AddClause()
+		if c.Solve() {
+			t.Errorf("seed %d: clone satisfiable with an empty clause", seed)
+		}
+		d.SetConflictBudget(-1)
+		if d.Solve() {
+			checkModel(t, "original", p, d.Model())
+		}
+	}
+}
+
+func TestSimp_Clone(t *testing.T) {
+	p := randomProblem(3, 60, 200)
+	s := NewSimp(nil, nil)
+	addProblem(s, p)
+	if !s.Eliminate(false) {
+		t.Fatal("unsatisfiable")
+	}
+	n := s.NumClause()
+
+	c := s.Clone()
+	for v := 1; v <= p.NumVar; v++ {
+		if !c.IsEliminated(Var(v)) {
+			c.AddClause(Literal(Var(v), true))
+			break
+		}
+	}
+	if !c.Solve() {
+		t.Fatal("clone unsatisfiable")
+	}
+	checkModel(t, "clone", p, c.Model())
+	if s.NumClause() != n {
+		t.Errorf("original has %d clauses (expected %d)", s.NumClause(), n)
+	}
+	if !s.Solve() {
+		t.Fatal("original unsatisfiable")
+	}
+	checkModel(t, "original", p, s.Model())
+}
+
+func TestDPLL_Clone_manyDecisions(t *testing.T) {
+	p := randomProblem(1, 300, 1278)
+	d := New(&Opt{RandVarFreq: 0.5})
+	addProblem(d, p)
+	d.SetConflictBudget(20000)
+	if !d.SolveLimited().IsUndef() {
+		t.Skip("search completed within the budget")
+	}
+	if d.ndecisions < 10000 {
+		t.Fatalf("%d decisions", d.ndecisions)
+	}
+
+	// the generator state is copied rather than reproduced by drawing from
+	// the generator again
+	seed := d.randSeed
+	c := d.Clone()
+	if d.randSeed != seed || c.randSeed != seed {
+		t.Fatalf("random state %v %v (expected %v)", d.randSeed, c.randSeed, seed)
+	}
+	d.SetConflictBudget(1000)
+	c.SetConflictBudget(1000)
+	status := d.SolveLimited()
+	cstatus := c.SolveLimited()
+	if cstatus != status || c.ndecisions != d.ndecisions || c.nrandDecisions != d.nrandDecisions {
+		t.Errorf("clone %v after %d decisions (expected %v after %d)", cstatus, c.ndecisions, status, d.ndecisions)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/aiger"
+	_ "github.com/bmatsuo/dpll/dimacs/xzstd" // xz and zstd input
+)
+
+func main() {
+	verbosity := flag.Int("v", 1, "verbosity level")
+	maxFrames := flag.Int("k", 20, "maximum number of time frames to check")
+	prop := flag.Int("p", 0, "index of the bad-state property to check")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatalf("%s expects exactly one argument", os.Args[0])
+	}
+	g, err := aiger.DecodeFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(g.Bad) == 0 {
+		// outputs are bad states in graphs predating AIGER 1.9
+		g.Bad = g.Outputs
+	}
+	if *prop < 0 || *prop >= len(g.Bad) {
+		log.Fatalf("property %d does not exist (%d properties)", *prop, len(g.Bad))
+	}
+	if *verbosity >= 1 {
+		log.Printf("inputs %d latches %d ands %d", len(g.Inputs), len(g.Latches), len(g.Ands))
+	}
+
+	d := dpll.New(&dpll.Opt{
+		Verbosity: *verbosity - 1,
+	})
+	b := aiger.NewBMC(d, g, *prop)
+	status := dpll.LFalse
+	for status.IsFalse() && b.Bound() < *maxFrames {
+		status = b.Step()
+		if *verbosity >= 1 && status.IsFalse() {
+			log.Printf("frame %d: bad state unreachable", b.Bound()-1)
+		}
+	}
+	if status.IsTrue() {
+		if *verbosity >= 1 {
+			log.Printf("frame %d: bad state reached", b.Bound())
+		}
+		err = aiger.EncodeWitness(os.Stdout, b.Witness())
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(10)
+	}
+	fmt.Printf("2\nb%d\n.\n", *prop)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -9,24 +10,71 @@ import (
 	"time"
 
 	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/cmd/internal/cmdutil"
+	"github.com/bmatsuo/dpll/dimacs"
+	_ "github.com/bmatsuo/dpll/dimacs/xzstd" // xz and zstd input and output
+)
+
+// exit codes
+const (
+	UNKNOWN = 0
+	FAIL    = 1
+	PARSE   = 3
+	SAT     = 10
+	UNSAT   = 20
 )
 
 func main() {
 	runtime.GOMAXPROCS(1)
 	verbosity := flag.Int("v", 1, "verbosity level")
+	output := flag.String("o", "", "path to write the solution (default stdout)")
+	verify := flag.String("verify", "", "check the solution at the given path instead of solving")
+	format := flag.String("format", "text", "output format (text or json)")
+	timeout := flag.Duration("timeout", 0, "stop the search after the given duration and report partial results")
+	memlimit := flag.Uint64("memlimit", 0, "stop the search if the heap exceeds the given number of megabytes")
+	lenient := flag.Bool("lenient", false, "decode leniently: clauses may span lines and the clause count need not match the header")
 	flag.Parse()
 	if flag.NArg() != 1 {
 		log.Fatalf("%s expects exactly one argument", os.Args[0])
 	}
+	if *format != "text" && *format != "json" {
+		log.Fatalf("invalid output format: %q", *format)
+	}
+	if *verify != "" {
+		err := cmdutil.VerifySolution(flag.Arg(0), *verify, *lenient)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("solution verified")
+		return
+	}
 	d := dpll.New(&dpll.Opt{
 		Verbosity: *verbosity,
 	})
+	decodeFile := dpll.DecodeFile
+	if *lenient {
+		decodeFile = dpll.DecodeFileLenient
+	}
 	parseStart := time.Now()
-	_, err := dpll.DecodeFile(d, flag.Arg(0))
+	_, err := decodeFile(d, flag.Arg(0))
 	if err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		code := FAIL
+		var derr *dimacs.Error
+		if errors.As(err, &derr) {
+			code = PARSE
+		}
+		res := &cmdutil.Result{}
+		res.SetError(err)
+		err = res.Write(*output, *format)
+		if err != nil {
+			log.Print(err)
+		}
+		os.Exit(code)
 	}
 	parseEnd := time.Now()
+	res := &cmdutil.Result{}
+	res.Time.Parse = parseEnd.Sub(parseStart).Seconds()
 	log.Printf("============================[ Problem Statistics ]=============================")
 	if d.Verbosity >= 1 {
 		log.Printf("|  Number of variables:  %12d                                         |", d.NumVar())
@@ -34,7 +82,16 @@ func main() {
 		dur := parseEnd.Sub(parseStart)
 		log.Printf("|  Parse time:           %12v                                         |", dur-dur%time.Microsecond)
 	}
-	if !d.Simplify() {
+	if *timeout > 0 {
+		d.SetTimeBudget(*timeout)
+	}
+	if *memlimit > 0 {
+		d.SetMemoryBudget(*memlimit << 20)
+	}
+	ok := d.Simplify()
+	simplifyEnd := time.Now()
+	res.Time.Simplify = simplifyEnd.Sub(parseEnd).Seconds()
+	if !ok {
 		// TODO: handle output for non-tty outputs
 		if d.Verbosity >= 1 {
 			log.Printf("===============================================================================")
@@ -43,23 +100,38 @@ func main() {
 			log.Println()
 		}
 		fmt.Fprintln(os.Stderr)
-		fmt.Println("UNSATISFIABLE")
-		os.Exit(20)
+		res.SetSolution(dpll.NewSolution(dpll.LFalse, nil), d.Stats())
+		err := res.Write(*output, *format)
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(UNSAT)
 	}
 
 	solution := d.SolveLimited()
-	if d.Verbosity >= 1 {
+	res.Time.Search = time.Since(simplifyEnd).Seconds()
+	limit := dpll.NoLimit
+	if solution.IsUndef() {
+		limit = d.Limit()
+	}
+	if d.Verbosity >= 1 || limit != dpll.NoLimit {
 		d.PrintStats()
 		fmt.Fprintln(os.Stderr)
 	}
+	res.SetSolution(dpll.NewSolution(solution, d.Model()), d.Stats())
+	if limit != dpll.NoLimit {
+		progress := d.Progress()
+		res.SetLimit(limit.String(), &progress)
+	}
+	err = res.Write(*output, *format)
+	if err != nil {
+		log.Fatal(err)
+	}
 	if solution.IsTrue() {
-		fmt.Println("SATISFIABLE")
-		os.Exit(10)
+		os.Exit(SAT)
 	} else if solution.IsFalse() {
-		fmt.Println("UNSATISFIABLE")
-		os.Exit(20)
+		os.Exit(UNSAT)
 	} else {
-		fmt.Println("INDETERMINATE")
-		os.Exit(0)
+		os.Exit(UNKNOWN)
 	}
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -11,6 +12,9 @@ import (
 	"time"
 
 	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/cmd/internal/cmdutil"
+	"github.com/bmatsuo/dpll/dimacs"
+	_ "github.com/bmatsuo/dpll/dimacs/xzstd" // xz and zstd input and output
 )
 
 func init() {
@@ -21,29 +25,68 @@ func main() {
 	cpuProfile := flag.String("cpuprofile", "", "path to write a pprof cpu profile for execution")
 	attemptSolve := flag.Bool("solve", true, "do not attempt to solve the problem")
 	verbosity := flag.Int("v", 1, "verbosity level")
+	output := flag.String("o", "", "path to write the solution (default stdout)")
+	verify := flag.String("verify", "", "check the solution at the given path instead of solving")
+	format := flag.String("format", "text", "output format (text or json)")
+	timeout := flag.Duration("timeout", 0, "stop the search after the given duration and report partial results")
+	memlimit := flag.Uint64("memlimit", 0, "stop the search if the heap exceeds the given number of megabytes")
+	lenient := flag.Bool("lenient", false, "decode leniently: clauses may span lines and the clause count need not match the header")
+	cnf := flag.String("cnf", "", "path to write the simplified problem, with its variables renumbered")
+	recPath := flag.String("rec", "", "path to write the reconstruction of models of the simplified problem")
 	flag.Parse()
 	if flag.NArg() != 1 {
 		log.Fatalf("%s expects exactly one argument", os.Args[0])
 	}
+	if *format != "text" && *format != "json" {
+		log.Fatalf("invalid output format: %q", *format)
+	}
+	if *verify != "" {
+		err := cmdutil.VerifySolution(flag.Arg(0), *verify, *lenient)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("solution verified")
+		return
+	}
 
 	exitCode := 0
 	const (
 		SAT   = 10
 		UNSAT = 20
 		FAIL  = 1
+		PARSE = 3
 	)
+	var model []dpll.LBool
+	var solver *dpll.Simp
+	stopped := dpll.NoLimit // the limit which stopped the search
+	var progress float64
+	res := &cmdutil.Result{}
 	defer func() {
 		if e := recover(); e != nil {
 			panic(e)
 		}
 
+		status := dpll.LUndef
 		switch exitCode {
-		case 10:
-			fmt.Println("SATISFIABLE")
-		case 20:
-			fmt.Println("UNSATISFIABLE")
-		default:
-			fmt.Println("INDETERMINATE")
+		case SAT:
+			status = dpll.LTrue
+		case UNSAT:
+			status = dpll.LFalse
+		}
+		if res.Error == "" {
+			var stats dpll.SimpStats
+			if solver != nil {
+				stats = solver.Stats()
+			}
+			res.SetSolution(dpll.NewSolution(status, model), stats)
+			if stopped != dpll.NoLimit {
+				res.SetLimit(stopped.String(), &progress)
+			}
+		}
+		err := res.Write(*output, *format)
+		if err != nil {
+			log.Print(err)
+			exitCode = FAIL
 		}
 
 		os.Exit(exitCode)
@@ -53,6 +96,7 @@ func main() {
 		fcpu, err := os.Create(*cpuProfile)
 		if err != nil {
 			log.Printf("failed creating pprof file: %v", err)
+			res.SetError(err)
 			exitCode = FAIL
 			return
 		}
@@ -61,23 +105,35 @@ func main() {
 		err = pprof.StartCPUProfile(fcpu)
 		if err != nil {
 			log.Printf("failed to start profiling: %v", err)
+			res.SetError(err)
 			exitCode = FAIL
 			return
 		}
 		defer pprof.StopCPUProfile()
 	}
 
-	solver := dpll.NewSimp(&dpll.Opt{
+	solver = dpll.NewSimp(&dpll.Opt{
 		Verbosity: *verbosity,
 	}, nil)
 
+	decodeFile := dpll.DecodeFile
+	if *lenient {
+		decodeFile = dpll.DecodeFileLenient
+	}
 	parseStart := time.Now()
-	_, err := dpll.DecodeFile(solver, flag.Arg(0))
+	_, err := decodeFile(solver, flag.Arg(0))
 	if err != nil {
 		log.Print(err)
+		res.SetError(err)
+		exitCode = FAIL
+		var derr *dimacs.Error
+		if errors.As(err, &derr) {
+			exitCode = PARSE
+		}
 		return
 	}
 	parseEnd := time.Now()
+	res.Time.Parse = parseEnd.Sub(parseStart).Seconds()
 
 	log.Printf("============================[ Problem Statistics ]=============================")
 	if *verbosity >= 1 {
@@ -87,6 +143,12 @@ func main() {
 		log.Printf("|  Parse time:           %12v                                         |", dur-dur%time.Microsecond)
 	}
 
+	if *timeout > 0 {
+		solver.SetTimeBudget(*timeout)
+	}
+	if *memlimit > 0 {
+		solver.SetMemoryBudget(*memlimit << 20)
+	}
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, os.Interrupt)
 	go func(c chan os.Signal) {
@@ -99,12 +161,23 @@ func main() {
 
 	solver.Eliminate(true)
 	simplifyEnd := time.Now()
+	res.Time.Simplify = simplifyEnd.Sub(parseEnd).Seconds()
 	if *verbosity >= 1 {
 		dur := simplifyEnd.Sub(parseEnd)
 		log.Printf("|  Simplify time:        %12v                                         |", dur-dur%time.Microsecond)
 		log.Printf("|                                                                             |")
 	}
 
+	if *cnf != "" || *recPath != "" {
+		err := cmdutil.WriteSimplified(solver, *cnf, *recPath)
+		if err != nil {
+			log.Print(err)
+			res.SetError(err)
+			exitCode = FAIL
+			return
+		}
+	}
+
 	if !solver.Okay() {
 		if *verbosity >= 1 {
 			log.Printf("===============================================================================")
@@ -120,6 +193,7 @@ func main() {
 	solution := dpll.LUndef
 	if *attemptSolve {
 		solution = solver.SolveLimited()
+		res.Time.Search = time.Since(simplifyEnd).Seconds()
 	} else {
 		if *verbosity >= 1 {
 			log.Printf("===============================================================================")
@@ -130,17 +204,26 @@ func main() {
 		}
 	}
 
-	if *verbosity >= 1 {
+	limit := dpll.NoLimit
+	if solution.IsUndef() {
+		limit = solver.Limit()
+	}
+	if *verbosity >= 1 || limit != dpll.NoLimit {
 		solver.PrintStats()
 		fmt.Fprintln(os.Stderr)
 	}
 	if solution.IsTrue() {
+		model = solver.Model()
 		exitCode = SAT
 		return
 	} else if solution.IsFalse() {
 		exitCode = UNSAT
 		return
 	} else {
+		if limit != dpll.NoLimit {
+			stopped = limit
+			progress = solver.Progress()
+		}
 		exitCode = 0
 		return
 	}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/bmatsuo/dpll/cmd/internal/cmdutil"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// runCheck checks that a solution file states a model of a problem.  Either
+// file may be read from standard input.
+func runCheck(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	lenient := fs.Bool("lenient", false, "decode leniently: clauses may span lines and the clause count need not match the header")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return FAIL
+	}
+	if fs.Arg(0) == fs.Arg(1) && (fs.Arg(0) == "-" || fs.Arg(0) == "") {
+		log.Print("the problem and solution cannot both be read from standard input")
+		return FAIL
+	}
+	p, err := cmdutil.DecodeProblem(fs.Arg(0), *lenient)
+	if err != nil {
+		log.Print(err)
+		return errorCode(err)
+	}
+	var sol *dimacs.Solution
+	if fs.Arg(1) == "-" {
+		sol, err = dimacs.DecodeSolution(os.Stdin)
+	} else {
+		sol, err = dimacs.DecodeSolutionFile(fs.Arg(1))
+	}
+	if err != nil {
+		log.Print(err)
+		return errorCode(err)
+	}
+	err = sol.Check(p)
+	if err != nil {
+		log.Print(err)
+		return FAIL
+	}
+	log.Printf("solution verified")
+	return 0
+}
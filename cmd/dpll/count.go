@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/cmd/internal/cmdutil"
+	"github.com/bmatsuo/dpll/count"
+)
+
+func runCount(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	opt := optFlags(fs)
+	approx := fs.Bool("approx", false, "compute an approximate count")
+	aopt := &count.ApproxOpt{}
+	*aopt = *count.DefaultApproxOpt
+	fs.Float64Var(&aopt.Epsilon, "epsilon", aopt.Epsilon, "tolerance of an approximate count")
+	fs.Float64Var(&aopt.Delta, "delta", aopt.Delta, "probability that an approximate count is outside the tolerance")
+	fs.Int64Var(&aopt.Seed, "approx-seed", aopt.Seed, "seed of the hash functions of an approximate count")
+	project := fs.String("project", "", "comma separated variables onto which the count is projected")
+	c := commonFlags(fs)
+	path := c.parse(fs, args)
+	setProcs(1)
+
+	res := &cmdutil.Result{}
+	stopProfile, err := c.startProfile()
+	if err != nil {
+		return c.fail(res, err)
+	}
+	defer stopProfile()
+
+	parseStart := time.Now()
+	p, err := cmdutil.DecodeProblem(path, c.lenient)
+	if err != nil {
+		return c.fail(res, err)
+	}
+	var vars []dpll.Var
+	if *project != "" {
+		for _, f := range strings.Split(*project, ",") {
+			v, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil || v <= 0 || v > p.NumVar {
+				return c.fail(res, fmt.Errorf("invalid projected variable: %q", f))
+			}
+			vars = append(vars, dpll.Var(v))
+		}
+	}
+	parseEnd := time.Now()
+	res.Time.Parse = parseEnd.Sub(parseStart).Seconds()
+	logProblem(opt.Verbosity, p.NumVar, len(p.Clauses), parseEnd.Sub(parseStart))
+
+	// the counter solves many small problems, which are only logged at
+	// higher verbosity.
+	sopt := *opt
+	sopt.Verbosity--
+	counter := count.New(p, vars, &sopt)
+	stop := c.watchLimits(counter.Interrupt)
+	defer stop()
+
+	var n *big.Int
+	if *approx {
+		n, err = counter.Approx(aopt)
+	} else {
+		n, err = counter.Exact()
+	}
+	res.Time.Search = time.Since(parseEnd).Seconds()
+	if err == count.ErrInterrupted {
+		res.Status = "UNKNOWN"
+		res.Text = func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, "s UNKNOWN")
+			return err
+		}
+		if limit := c.limit(); limit != "" {
+			res.SetLimit(limit, nil)
+		}
+		return c.exit(res, UNKNOWN)
+	}
+	if err != nil {
+		return c.fail(res, err)
+	}
+	res.Status = "SATISFIABLE"
+	code := SAT
+	if n.Sign() == 0 {
+		res.Status = "UNSATISFIABLE"
+		code = UNSAT
+	}
+	res.Count = n.String()
+	if opt.Verbosity >= 1 {
+		log.Printf("Models: %s", res.Count)
+	}
+	res.Text = func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "s mc %s\n", res.Count)
+		return err
+	}
+	return c.exit(res, code)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/cmd/internal/cmdutil"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// runExtend extends a solution of a problem written by the simplify command
+// to a solution of the original problem.  Unsatisfiable and unknown
+// solutions are passed through.
+func runExtend(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	recPath := fs.String("rec", "", "path of the reconstruction written by the simplify command")
+	c := &common{}
+	fs.StringVar(&c.output, "o", "", "path to write the extended solution (default stdout)")
+	fs.StringVar(&c.format, "format", "text", "output format (text or json)")
+	path := c.parse(fs, args)
+	if *recPath == "" {
+		log.Print("the -rec flag is required")
+		return FAIL
+	}
+
+	res := &cmdutil.Result{}
+	rec, err := readReconstruction(*recPath)
+	if err != nil {
+		return c.fail(res, err)
+	}
+	sol, err := decodeSolution(path)
+	if err != nil {
+		return c.fail(res, err)
+	}
+	switch sol.Status {
+	case dimacs.Unsatisfiable:
+		res.SetSolution(sol, nil)
+		return c.exit(res, UNSAT)
+	case dimacs.Satisfiable:
+	default:
+		res.SetSolution(sol, nil)
+		return c.exit(res, UNKNOWN)
+	}
+
+	model := make([]dpll.LBool, len(rec.Map))
+	for v := range model {
+		model[v] = dpll.LUndef
+	}
+	for _, x := range sol.Model {
+		if x.Var() >= len(model) {
+			return c.fail(res, &dimacs.Error{
+				Kind: dimacs.ErrRange,
+				Msg:  "solution assigns a variable outside the simplified problem",
+			})
+		}
+		model[x.Var()] = dpll.LiftBool(!x.Neg())
+	}
+	res.SetSolution(dpll.NewSolution(dpll.LTrue, rec.Extend(model)), nil)
+	return c.exit(res, SAT)
+}
+
+func readReconstruction(path string) (*dpll.Reconstruction, error) {
+	f, err := dimacs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return dpll.ReadReconstruction(f)
+}
+
+// decodeSolution decodes the solution at path, or standard input if path is
+// empty or "-".
+func decodeSolution(path string) (*dimacs.Solution, error) {
+	var r io.Reader = os.Stdin
+	if path != "" && path != "-" {
+		f, err := dimacs.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return dimacs.DecodeSolution(r)
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/cmd/internal/cmdutil"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// newFlagSet returns a FlagSet for cmd which prints its usage to stderr.
+func newFlagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: dpll %s [flags] %s\n\n%s\n\nflags:\n", cmd.Name, cmd.Args, cmd.Short)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// enumFlag is a flag.Value taking one of a fixed set of names.  Set is called
+// with the index of the name.  Empty names are not accepted.
+type enumFlag struct {
+	names []string
+	value int
+	set   func(int)
+}
+
+func (f *enumFlag) String() string {
+	if f == nil || f.value < 0 || f.value >= len(f.names) {
+		return ""
+	}
+	return f.names[f.value]
+}
+
+func (f *enumFlag) Set(s string) error {
+	var names []string
+	for i, name := range f.names {
+		if name == "" {
+			continue
+		}
+		if name == s {
+			f.value = i
+			f.set(i)
+			return nil
+		}
+		names = append(names, name)
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(names, ", "))
+}
+
+// optFlags defines a flag in fs for each field of dpll.Opt, except the clause
+// sharing callbacks, and returns the options which are set when fs is
+// parsed.  The defaults of the flags are those of the solver.  Solvers must
+// be created with newDPLL or newSimp so that flags set to zero are honored.
+func optFlags(fs *flag.FlagSet) *dpll.Opt {
+	def := dpll.New(nil).Opt
+	o := &dpll.Opt{}
+	*o = def
+	o.Verbosity = 1
+	fs.IntVar(&o.Verbosity, "v", o.Verbosity, "verbosity level")
+	fs.Float64Var(&o.VarDecay, "var-decay", o.VarDecay, "activity decay factor of variables")
+	fs.Float64Var(&o.ClauseDecay, "clause-decay", o.ClauseDecay, "activity decay factor of clauses")
+	fs.Float64Var(&o.RandVarFreq, "rand-var-freq", o.RandVarFreq, "frequency of random decisions")
+	fs.Int64Var(&o.RandSeed, "rand-seed", o.RandSeed, "seed of the pseudorandom number generator")
+	fs.BoolVar(&o.NoLubyRestart, "no-luby-restart", o.NoLubyRestart, "use geometric restarts instead of the Luby sequence")
+	fs.Var(&enumFlag{
+		names: []string{"", "none", "basic", "deep"},
+		value: int(o.CCMin),
+		set:   func(i int) { o.CCMin = dpll.CCMinMode(i) },
+	}, "ccmin", "conflict clause minimization (none, basic, deep)")
+	fs.Var(&enumFlag{
+		names: []string{"", "none", "limited", "full"},
+		value: int(o.PhaseSaving),
+		set:   func(i int) { o.PhaseSaving = dpll.PhaseSavingLevel(i) },
+	}, "phase-saving", "phase saving (none, limited, full)")
+	fs.BoolVar(&o.RandPol, "rand-pol", o.RandPol, "choose decision polarities randomly")
+	fs.BoolVar(&o.RandInitAct, "rand-init-act", o.RandInitAct, "initialize variable activities randomly")
+	fs.IntVar(&o.MinLearnt, "min-learnt", o.MinLearnt, "minimum limit on learnt clauses")
+	fs.Float64Var(&o.GarbageFrac, "garbage-frac", o.GarbageFrac, "fraction of wasted clause memory allowed before garbage collection")
+	fs.IntVar(&o.RestartFirst, "restart-first", o.RestartFirst, "initial restart limit in conflicts")
+	fs.Float64Var(&o.RestartIncr, "restart-incr", o.RestartIncr, "factor by which the restart limit increases")
+	fs.Float64Var(&o.LearntFraction, "learnt-fraction", o.LearntFraction, "initial limit on learnt clauses as a fraction of the original clauses")
+	fs.Float64Var(&o.LearntIncr, "learnt-incr", o.LearntIncr, "factor by which the limit on learnt clauses increases")
+	fs.IntVar(&o.LearntAdjustConfl, "learnt-adjust-confl", o.LearntAdjustConfl, "conflicts before the first adjustment of the learnt clause limit")
+	fs.Float64Var(&o.LearntAdjustIncr, "learnt-adjust-incr", o.LearntAdjustIncr, "factor by which the conflicts between adjustments increase")
+	fs.BoolVar(&o.Probe, "probe", o.Probe, "probe for failed literals at restarts")
+	fs.Int64Var(&o.ProbeBudget, "probe-budget", o.ProbeBudget, "propagations allowed in each round of probing")
+	fs.BoolVar(&o.Vivify, "vivify", o.Vivify, "vivify clauses at restarts")
+	fs.Int64Var(&o.VivifyBudget, "vivify-budget", o.VivifyBudget, "propagations allowed in each round of vivification")
+	fs.BoolVar(&o.VerifyModel, "verify-model", o.VerifyModel, "check models against the original clauses (costly)")
+	return o
+}
+
+// isSet returns true if the flag name was given on the command line.
+func isSet(fs *flag.FlagSet, name string) bool {
+	var set bool
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// newDPLL returns a solver with exactly the options opt.  dpll.New replaces
+// zero fields with defaults, so opt is assigned again once the solver is
+// created.
+func newDPLL(opt *dpll.Opt) *dpll.DPLL {
+	d := dpll.New(opt)
+	d.Opt = *opt
+	return d
+}
+
+// newSimp behaves like newDPLL for a dpll.Simp.  NewSimp moves VerifyModel
+// from opt to the SimpOpt, so that models are verified once they are
+// extended to eliminated variables, and newSimp preserves the move.
+func newSimp(opt *dpll.Opt, sopt *dpll.SimpOpt) *dpll.Simp {
+	s := dpll.NewSimp(opt, sopt)
+	verify := s.VerifyModel
+	*s.Opt() = *opt
+	s.Opt().VerifyModel = false
+	s.SimpOpt = *sopt
+	s.VerifyModel = verify
+	return s
+}
+
+// simpOptFlags defines a flag in fs for each field of dpll.SimpOpt and returns
+// the options which are set when fs is parsed.  VerifyModel is set by the
+// flag of optFlags.
+func simpOptFlags(fs *flag.FlagSet) *dpll.SimpOpt {
+	o := &dpll.SimpOpt{}
+	*o = dpll.NewSimp(nil, nil).SimpOpt
+	fs.BoolVar(&o.Asymm, "asymm", o.Asymm, "shrink clauses by asymmetric branching")
+	fs.BoolVar(&o.RCheck, "rcheck", o.RCheck, "check if a clause is already implied (costly)")
+	fs.BoolVar(&o.NoElim, "no-elim", o.NoElim, "do not eliminate variables")
+	fs.BoolVar(&o.NoExtend, "no-extend", o.NoExtend, "do not extend models to eliminated variables")
+	fs.BoolVar(&o.NoGates, "no-gates", o.NoGates, "do not use gate definitions in variable elimination")
+	fs.IntVar(&o.Grow, "grow", o.Grow, "clauses a variable elimination may add")
+	fs.IntVar(&o.ClauseLimit, "clause-limit", o.ClauseLimit, "longest resolvent allowed in variable elimination")
+	fs.IntVar(&o.SubsumptionLimit, "subsumption-limit", o.SubsumptionLimit, "longest clause checked for subsumption")
+	fs.Float64Var(&o.SimpGarbageFrac, "simp-garbage-frac", o.SimpGarbageFrac, "fraction of wasted clause memory allowed during simplification")
+	fs.BoolVar(&o.Inprocess, "inprocess", o.Inprocess, "simplify the clauses again during search")
+	fs.IntVar(&o.InprocessInterval, "inprocess-interval", o.InprocessInterval, "conflicts between rounds of inprocessing")
+	fs.Float64Var(&o.InprocessEffort, "inprocess-effort", o.InprocessEffort, "simplification steps in a round as a fraction of propagations")
+	return o
+}
+
+// common holds the flags shared by the commands.
+type common struct {
+	output     string
+	format     string
+	cpuprofile string
+	timeout    time.Duration
+	memlimit   uint64
+	lenient    bool
+
+	mut    sync.Mutex
+	reason string // limit which interrupted the search
+}
+
+func commonFlags(fs *flag.FlagSet) *common {
+	c := &common{}
+	fs.StringVar(&c.output, "o", "", "path to write the result (default stdout)")
+	fs.StringVar(&c.format, "format", "text", "output format (text or json)")
+	fs.StringVar(&c.cpuprofile, "cpuprofile", "", "path to write a pprof cpu profile")
+	fs.DurationVar(&c.timeout, "timeout", 0, "stop the search after the given wall-clock time and report partial results")
+	fs.Uint64Var(&c.memlimit, "memlimit", 0, "stop the search when the heap exceeds the given number of megabytes")
+	fs.BoolVar(&c.lenient, "lenient", false, "decode leniently: clauses may span lines and the clause count need not match the header")
+	return c
+}
+
+// parse parses args, which may contain at most one positional argument, the
+// path of the input.  parse exits the process if the arguments are invalid.
+func (c *common) parse(fs *flag.FlagSet, args []string) (path string) {
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(FAIL)
+	}
+	if c.format != "text" && c.format != "json" {
+		log.Printf("invalid output format: %q", c.format)
+		os.Exit(FAIL)
+	}
+	return fs.Arg(0)
+}
+
+// startProfile starts the cpu profile, if one was requested, and returns a
+// function stopping it.
+func (c *common) startProfile() (stop func(), err error) {
+	if c.cpuprofile == "" {
+		return func() {}, nil
+	}
+	f, err := os.Create(c.cpuprofile)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating pprof file: %v", err)
+	}
+	err = pprof.StartCPUProfile(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to start profiling: %v", err)
+	}
+	return func() {
+		pprof.StopCPUProfile()
+		f.Close()
+	}, nil
+}
+
+// budgeter is the part of dpll.DPLL and dpll.Simp which limits a search.
+type budgeter interface {
+	SetTimeBudget(dur time.Duration)
+	SetMemoryBudget(n uint64)
+}
+
+// setBudgets applies the -timeout and -memlimit flags to the budgets of s.
+func (c *common) setBudgets(s budgeter) {
+	if c.timeout > 0 {
+		s.SetTimeBudget(c.timeout)
+	}
+	if c.memlimit > 0 {
+		s.SetMemoryBudget(c.memlimit << 20)
+	}
+}
+
+// watch calls interrupt once if the process receives SIGINT.  Watching ends
+// when stop is called.  The -timeout and -memlimit flags are enforced by the
+// budgets of the solver, see setBudgets.
+func (c *common) watch(interrupt func()) (stop func()) {
+	return c.startWatch(interrupt, false)
+}
+
+// watchLimits behaves like watch but also calls interrupt when the timeout
+// elapses or the heap grows beyond the memory limit, for searches whose
+// solvers are not accessible.  The limit reached is returned by limit.
+func (c *common) watchLimits(interrupt func()) (stop func()) {
+	return c.startWatch(interrupt, true)
+}
+
+func (c *common) startWatch(interrupt func(), limits bool) (stop func()) {
+	var once sync.Once
+	fire := func(reason string) {
+		once.Do(func() {
+			log.Printf("%s: interrupting the search", reason)
+			c.mut.Lock()
+			c.reason = reason
+			c.mut.Unlock()
+			interrupt()
+		})
+	}
+	done := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	var timeout <-chan time.Time
+	var timer *time.Timer
+	if limits && c.timeout > 0 {
+		timer = time.NewTimer(c.timeout)
+		timeout = timer.C
+	}
+	var tick <-chan time.Time
+	var ticker *time.Ticker
+	if limits && c.memlimit > 0 {
+		ticker = time.NewTicker(100 * time.Millisecond)
+		tick = ticker.C
+	}
+	go func() {
+		var m runtime.MemStats
+		for {
+			select {
+			case <-done:
+				return
+			case <-sig:
+				signal.Stop(sig)
+				fire(dpll.LimitInterrupt.String())
+			case <-timeout:
+				fire(dpll.LimitTime.String())
+			case <-tick:
+				runtime.ReadMemStats(&m)
+				if m.HeapAlloc>>20 >= c.memlimit {
+					fire(dpll.LimitMemory.String())
+				}
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		if timer != nil {
+			timer.Stop()
+		}
+		if ticker != nil {
+			ticker.Stop()
+		}
+		close(done)
+	}
+}
+
+// limit returns the limit which interrupted the search, or an empty string.
+func (c *common) limit() string {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.reason
+}
+
+// decode decodes the CNF problem at path into s, leniently if the -lenient
+// flag was given.
+func (c *common) decode(s dpll.Solver, path string) error {
+	r, err := cmdutil.OpenInput(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if c.lenient {
+		_, err = dpll.DecodeLenient(s, r)
+	} else {
+		_, err = dpll.Decode(s, r)
+	}
+	return err
+}
+
+// literals converts a clause to solver literals.
+func literals(c []dimacs.Lit) []dpll.Lit {
+	ps := make([]dpll.Lit, len(c))
+	for i, x := range c {
+		ps[i] = dpll.LiteralInt(int(x))
+	}
+	return ps
+}
+
+// errorCode returns the exit code of a command which failed with err.
+func errorCode(err error) int {
+	var derr *dimacs.Error
+	if errors.As(err, &derr) {
+		return PARSE
+	}
+	return FAIL
+}
+
+// exit writes r and returns code, or FAIL if r could not be written.
+func (c *common) exit(r *cmdutil.Result, code int) int {
+	err := r.Write(c.output, c.format)
+	if err != nil {
+		log.Print(err)
+		return FAIL
+	}
+	return code
+}
+
+// fail records err in r and exits with the code of err.
+func (c *common) fail(r *cmdutil.Result, err error) int {
+	log.Print(err)
+	r.SetError(err)
+	c.exit(r, 0)
+	return errorCode(err)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func parseOptFlags(t *testing.T, args ...string) (*flag.FlagSet, *dpll.Opt, *dpll.SimpOpt) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	opt := optFlags(fs)
+	sopt := simpOptFlags(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fs, opt, sopt
+}
+
+func TestOptFlags_default(t *testing.T) {
+	_, opt, sopt := parseOptFlags(t)
+	def := dpll.New(nil).Opt
+	opt.Verbosity = def.Verbosity
+	if d := newDPLL(opt); d.Opt.VarDecay != def.VarDecay || d.Opt.ExportMaxLen != def.ExportMaxLen || d.Opt.CCMin != def.CCMin {
+		t.Errorf("options %+v (expected %+v)", d.Opt, def)
+	}
+	if s := newSimp(opt, sopt); s.SimpOpt != dpll.NewSimp(nil, nil).SimpOpt {
+		t.Errorf("simp options %+v", s.SimpOpt)
+	}
+}
+
+func TestOptFlags_zero(t *testing.T) {
+	fs, opt, sopt := parseOptFlags(t,
+		"-rand-var-freq=0",
+		"-garbage-frac=0",
+		"-restart-first=0",
+		"-probe-budget=0",
+		"-clause-limit=0",
+		"-inprocess-effort=0",
+		"-verify-model",
+	)
+	if !isSet(fs, "rand-var-freq") || isSet(fs, "var-decay") {
+		t.Errorf("isSet")
+	}
+
+	d := newDPLL(opt)
+	if d.RandVarFreq != 0 || d.GarbageFrac != 0 || d.RestartFirst != 0 || d.ProbeBudget != 0 {
+		t.Errorf("zero options replaced: %+v", d.Opt)
+	}
+	s := newSimp(opt, sopt)
+	if o := s.Opt(); o.GarbageFrac != 0 || o.RestartFirst != 0 || o.VerifyModel {
+		t.Errorf("zero options replaced: %+v", *o)
+	}
+	if s.ClauseLimit != 0 || s.InprocessEffort != 0 || !s.VerifyModel {
+		t.Errorf("zero simp options replaced: %+v", s.SimpOpt)
+	}
+}
+
+func TestPortfolioRandVarFreq(t *testing.T) {
+	fs, opt, _ := parseOptFlags(t)
+	if f := portfolioRandVarFreq(fs, opt); f == 0 {
+		t.Errorf("default rand-var-freq %v", f)
+	}
+
+	fs, opt, _ = parseOptFlags(t, "-rand-var-freq=0")
+	p := &dimacs.Problem{NumVar: 2, Clauses: [][]dimacs.Lit{{1, 2}}}
+	popt := portfolioOpt{n: 3, randVarFreq: portfolioRandVarFreq(fs, opt)}
+	pf, ok := newPortfolio(p, opt, popt)
+	if !ok {
+		t.Fatal("unsatisfiable")
+	}
+	for i, d := range pf.solvers {
+		if d.RandVarFreq != 0 {
+			t.Errorf("solver %d: rand-var-freq %v", i, d.RandVarFreq)
+		}
+	}
+}
+
+func TestCommon_decode(t *testing.T) {
+	f, err := ioutil.TempFile("", "dpll-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("p cnf 3 1\n1 -2\n3 0\n")
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var derr *dimacs.Error
+	err = (&common{}).decode(dpll.New(nil), f.Name())
+	if !errors.As(err, &derr) {
+		t.Errorf("strict: %v", err)
+	}
+	d := dpll.New(nil)
+	err = (&common{lenient: true}).decode(d, f.Name())
+	if err != nil || d.NumClause() != 1 {
+		t.Errorf("lenient: %d clauses %v", d.NumClause(), err)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Command dpll solves and analyzes problems in DIMACS format.
+
+	dpll <command> [flags] [file]
+
+The commands are
+
+	solve     solve a CNF problem
+	simplify  simplify a CNF problem without searching for a model
+	extend    extend a solution of a simplified problem to the original problem
+	check     check a solution against a CNF problem
+	count     count the models of a CNF problem
+	maxsat    solve a weighted partial MaxSAT problem in WCNF format
+
+The simplify command can write the simplified problem, with its variables
+renumbered, and a reconstruction file, so that the problem may be solved by
+another solver.  The extend command turns a solution of the simplified problem
+into a solution of the original problem.
+
+	dpll simplify -cnf simp.cnf -rec simp.rec problem.cnf
+	othersolver simp.cnf > simp.sol
+	dpll extend -rec simp.rec simp.sol
+
+Problems are read from the named file, which may be compressed, or from
+standard input if the file is "-" or omitted.  Each clause must occupy a
+single line and the number of clauses must match the header unless the
+-lenient flag is given.  Every field of dpll.Opt but the clause sharing
+options and, for the commands which preprocess, every field of dpll.SimpOpt
+is available as a flag.  A flag set to zero or false is honored even where
+the solver default is different.  Run "dpll <command> -h" for the flags of a
+command.
+
+The search stops with an UNKNOWN result on SIGINT or when the -timeout or
+-memlimit flags are exceeded.  Before giving up on -memlimit the solver
+reduces its learnt clauses and collects garbage.  A search stopped by a limit
+prints its statistics and begins the text output with comment lines naming
+the limit and the estimated progress of the search.  The exit code is 10 for
+a satisfiable problem, 20 for an unsatisfiable problem, 0 for an unknown
+result, 1 for other failures and 3 for malformed input.  The check command
+exits with 0 if the solution is valid and the maxsat command exits with 30
+when an optimal model was found.
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	_ "github.com/bmatsuo/dpll/dimacs/xzstd" // xz and zstd input and output
+)
+
+// exit codes
+const (
+	UNKNOWN = 0
+	FAIL    = 1
+	PARSE   = 3
+	SAT     = 10
+	UNSAT   = 20
+	OPTIMUM = 30
+)
+
+// command is a subcommand of dpll.  Run is given the arguments following the
+// command name and returns the exit code.
+type command struct {
+	Name  string
+	Args  string
+	Short string
+	Run   func(cmd *command, args []string) int
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"solve", "[file]", "solve a CNF problem", runSolve},
+		{"simplify", "[file]", "simplify a CNF problem without searching for a model", runSimplify},
+		{"extend", "-rec file [solution]", "extend a solution of a simplified problem to the original problem", runExtend},
+		{"check", "problem solution", "check a solution against a CNF problem", runCheck},
+		{"count", "[file]", "count the models of a CNF problem", runCount},
+		{"maxsat", "[file]", "solve a weighted partial MaxSAT problem in WCNF format", runMaxSAT},
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: dpll <command> [flags] [args]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s%s\n", cmd.Name, cmd.Short)
+	}
+	fmt.Fprintf(os.Stderr, "\nrun \"dpll <command> -h\" for the flags of a command\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(FAIL)
+	}
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		usage()
+		return
+	}
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			os.Exit(cmd.Run(cmd, os.Args[2:]))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "dpll: unknown command %q\n", name)
+	usage()
+	os.Exit(FAIL)
+}
+
+// setProcs restricts the process to a single OS thread running Go code
+// unless solvers run in parallel.
+func setProcs(threads int) {
+	if threads <= 1 {
+		runtime.GOMAXPROCS(1)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/cmd/internal/cmdutil"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// runMaxSAT minimizes the weight of the unsatisfied soft clauses of a WCNF
+// problem.  Each soft clause of more than one literal is relaxed by a new
+// variable, which is given the weight of the clause in the objective.  The
+// text output is in the format of the MaxSAT evaluations.
+//
+//		o 7
+//		s OPTIMUM FOUND
+//		v 0110
+func runMaxSAT(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	opt := optFlags(fs)
+	oopt := &dpll.OptimizeOpt{}
+	fs.Var(&enumFlag{
+		names: []string{"linear", "binary"},
+		value: int(oopt.Strategy),
+		set:   func(i int) { oopt.Strategy = dpll.OptimizeStrategy(i) },
+	}, "strategy", "search for better models (linear, binary)")
+	c := commonFlags(fs)
+	path := c.parse(fs, args)
+	setProcs(1)
+
+	res := &cmdutil.Result{}
+	stopProfile, err := c.startProfile()
+	if err != nil {
+		return c.fail(res, err)
+	}
+	defer stopProfile()
+
+	parseStart := time.Now()
+	r, err := cmdutil.OpenInput(path)
+	if err != nil {
+		return c.fail(res, err)
+	}
+	p, err := dimacs.DecodeWCNF(r)
+	r.Close()
+	if err != nil {
+		return c.fail(res, err)
+	}
+
+	// the objective is minimized by many searches, which are only logged at
+	// higher verbosity.
+	sopt := *opt
+	sopt.Verbosity--
+	d := newDPLL(&sopt)
+	for d.NumVar() < p.NumVar {
+		d.NewVar(dpll.LUndef, true)
+	}
+	ok := true
+	for _, cl := range p.Hard {
+		ok = d.AddClause(literals(cl)...) && ok
+	}
+	var obj dpll.Objective
+	var offset int64 // weight of empty soft clauses
+	for _, cl := range p.Soft {
+		switch len(cl.Lits) {
+		case 0:
+			offset += cl.Weight
+		case 1:
+			obj = append(obj, dpll.WeightedLit{Lit: dpll.LiteralInt(int(cl.Lits[0])).Inverse(), Weight: cl.Weight})
+		default:
+			r := dpll.Literal(d.NewVar(dpll.LUndef, true), false)
+			d.AddClause(append(literals(cl.Lits), r)...)
+			obj = append(obj, dpll.WeightedLit{Lit: r, Weight: cl.Weight})
+		}
+	}
+	parseEnd := time.Now()
+	res.Time.Parse = parseEnd.Sub(parseStart).Seconds()
+	logProblem(opt.Verbosity, p.NumVar, len(p.Hard)+len(p.Soft), parseEnd.Sub(parseStart))
+	if opt.Verbosity >= 1 {
+		log.Printf("|  Number of soft clauses:%11d                                         |", len(p.Soft))
+	}
+
+	var model []dpll.LBool
+	status := dpll.LFalse
+	var cost int64
+	if ok {
+		c.setBudgets(d)
+		stop := c.watch(d.Interrupt)
+		defer stop()
+		oopt.Improve = func(model []dpll.LBool, costs []int64) {
+			if opt.Verbosity >= 1 {
+				log.Printf("o %d", costs[0]+offset)
+			}
+		}
+		var costs []int64
+		model, costs, status = d.Optimize([]dpll.Objective{obj}, oopt)
+		if model != nil {
+			model = model[:p.NumVar+1]
+			cost = costs[0] + offset
+		}
+	}
+	res.Time.Search = time.Since(parseEnd).Seconds()
+	limit := dpll.NoLimit
+	if status.IsUndef() {
+		limit = d.Limit()
+	}
+	if opt.Verbosity >= 1 || limit != dpll.NoLimit {
+		d.PrintStats()
+	}
+
+	res.Stats = d.Stats()
+	code := UNKNOWN
+	res.Status = "UNKNOWN"
+	switch {
+	case status.IsFalse():
+		code = UNSAT
+		res.Status = "UNSATISFIABLE"
+	case status.IsTrue():
+		code = OPTIMUM
+		res.Status = "OPTIMUM FOUND"
+	case model != nil:
+		code = SAT
+		res.Status = "SATISFIABLE"
+	}
+	if model != nil {
+		res.Cost = &cost
+		res.Model = dpll.NewSolution(dpll.LTrue, model).Model
+	}
+	res.Text = func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		if model != nil {
+			fmt.Fprintf(bw, "o %d\n", cost)
+		}
+		fmt.Fprintf(bw, "s %s\n", res.Status)
+		if model != nil {
+			bw.WriteString("v ")
+			for _, x := range model[1:] {
+				if x.IsTrue() {
+					bw.WriteByte('1')
+				} else {
+					bw.WriteByte('0')
+				}
+			}
+			bw.WriteByte('\n')
+		}
+		return bw.Flush()
+	}
+	if limit != dpll.NoLimit {
+		res.SetLimit(limit.String(), nil)
+	}
+	return c.exit(res, code)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"sync"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// portfolio solves a problem with solvers running in parallel.  The solvers
+// differ in their random seeds and initial activities and share their short
+// learnt clauses through Opt.ExportLearnt and Opt.ImportLearnt.
+type portfolio struct {
+	solvers []*dpll.DPLL
+
+	mut   sync.Mutex
+	queue [][][]dpll.Lit // clauses waiting to be imported by each solver
+}
+
+// portfolioOpt configures a portfolio.
+type portfolioOpt struct {
+	n           int     // number of solvers
+	maxLen      int     // longest learnt clause shared, or 0 for no limit
+	randVarFreq float64 // RandVarFreq of every solver but the first
+}
+
+// portfolioRandVarFreq returns the RandVarFreq of every portfolio solver but
+// the first.  Unless the -rand-var-freq flag of fs is given, random decisions
+// are made to diversify the searches.
+func portfolioRandVarFreq(fs *flag.FlagSet, opt *dpll.Opt) float64 {
+	if opt.RandVarFreq == 0 && !isSet(fs, "rand-var-freq") {
+		return 0.01
+	}
+	return opt.RandVarFreq
+}
+
+// newPortfolio returns a portfolio of popt.n solvers containing the clauses
+// of p.  Only the first solver logs its progress and uses opt unchanged.
+// newPortfolio returns false if the clauses are found to be unsatisfiable.
+func newPortfolio(p *dimacs.Problem, opt *dpll.Opt, popt portfolioOpt) (*portfolio, bool) {
+	n := popt.n
+	pf := &portfolio{queue: make([][][]dpll.Lit, n)}
+	ok := true
+	for i := 0; i < n; i++ {
+		i := i
+		o := *opt
+		if i > 0 {
+			o.Verbosity = 0
+			o.RandSeed += int64(i)
+			o.RandInitAct = true
+			o.RandVarFreq = popt.randVarFreq
+		}
+		o.ExportMaxLen = popt.maxLen
+		o.ExportLearnt = func(ps []dpll.Lit, lbd int) { pf.export(i, ps) }
+		o.ImportLearnt = func() [][]dpll.Lit { return pf.take(i) }
+		d := newDPLL(&o)
+		for d.NumVar() < p.NumVar {
+			d.NewVar(dpll.LUndef, true)
+		}
+		for _, c := range p.Clauses {
+			ok = d.AddClause(literals(c)...) && ok
+		}
+		pf.solvers = append(pf.solvers, d)
+	}
+	return pf, ok
+}
+
+// export queues a copy of ps for import by every solver except solver i.
+func (pf *portfolio) export(i int, ps []dpll.Lit) {
+	c := append([]dpll.Lit(nil), ps...)
+	pf.mut.Lock()
+	defer pf.mut.Unlock()
+	for j := range pf.queue {
+		if j != i {
+			pf.queue[j] = append(pf.queue[j], c)
+		}
+	}
+}
+
+// take returns the clauses queued for solver i.
+func (pf *portfolio) take(i int) [][]dpll.Lit {
+	pf.mut.Lock()
+	defer pf.mut.Unlock()
+	cs := pf.queue[i]
+	pf.queue[i] = nil
+	return cs
+}
+
+// Interrupt interrupts every solver.
+func (pf *portfolio) Interrupt() {
+	for _, d := range pf.solvers {
+		d.Interrupt()
+	}
+}
+
+// Solve runs the solvers in parallel until one of them finishes, at which
+// point the others are interrupted.  Solve returns the solver which finished
+// and its result.  If every solver was interrupted the first solver is
+// returned with LUndef.
+func (pf *portfolio) Solve() (*dpll.DPLL, dpll.LBool) {
+	type result struct {
+		i      int
+		status dpll.LBool
+	}
+	results := make(chan result, len(pf.solvers))
+	for i, d := range pf.solvers {
+		go func(i int, d *dpll.DPLL) {
+			results <- result{i, d.SolveLimited()}
+		}(i, d)
+	}
+	winner := result{0, dpll.LUndef}
+	for range pf.solvers {
+		r := <-results
+		if winner.status.IsUndef() && !r.status.IsUndef() {
+			winner = r
+			pf.Interrupt()
+		}
+	}
+	return pf.solvers[winner.i], winner.status
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/cmd/internal/cmdutil"
+)
+
+func runSimplify(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	opt := optFlags(fs)
+	sopt := simpOptFlags(fs)
+	cnf := fs.String("cnf", "", "path to write the simplified problem, with its variables renumbered")
+	recPath := fs.String("rec", "", "path to write the reconstruction used by the extend command")
+	c := commonFlags(fs)
+	path := c.parse(fs, args)
+	setProcs(1)
+
+	res := &cmdutil.Result{}
+	stopProfile, err := c.startProfile()
+	if err != nil {
+		return c.fail(res, err)
+	}
+	defer stopProfile()
+
+	s := newSimp(opt, sopt)
+	parseStart := time.Now()
+	err = c.decode(s, path)
+	if err != nil {
+		return c.fail(res, err)
+	}
+	parseEnd := time.Now()
+	res.Time.Parse = parseEnd.Sub(parseStart).Seconds()
+	logProblem(opt.Verbosity, s.NumVar(), s.NumClause(), parseEnd.Sub(parseStart))
+
+	c.setBudgets(s)
+	stop := c.watch(s.Interrupt)
+	defer stop()
+
+	ok := s.Eliminate(false)
+	res.Time.Simplify = time.Since(parseEnd).Seconds()
+	if opt.Verbosity >= 1 {
+		dur := time.Since(parseEnd)
+		log.Printf("|  Simplify time:        %12v                                         |", dur-dur%time.Microsecond)
+		log.Printf("|  Eliminated variables: %12d                                         |", s.Stats().EliminatedVars)
+		log.Printf("|  Remaining clauses:    %12d                                         |", s.NumClause())
+		log.Printf("===============================================================================")
+	}
+	if *cnf != "" || *recPath != "" {
+		err := cmdutil.WriteSimplified(s, *cnf, *recPath)
+		if err != nil {
+			return c.fail(res, err)
+		}
+	}
+	if !ok {
+		if opt.Verbosity >= 1 {
+			log.Printf("Solved by simplification")
+		}
+		res.SetSolution(dpll.NewSolution(dpll.LFalse, nil), s.Stats())
+		return c.exit(res, UNSAT)
+	}
+	res.SetSolution(dpll.NewSolution(dpll.LUndef, nil), s.Stats())
+	if limit := s.Limit(); limit != dpll.NoLimit {
+		res.SetLimit(limit.String(), nil)
+	}
+	return c.exit(res, UNKNOWN)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/cmd/internal/cmdutil"
+)
+
+// solver is the part of dpll.DPLL and dpll.Simp used by the solve command.
+type solver interface {
+	dpll.Solver
+	budgeter
+	Okay() bool
+	Model() []dpll.LBool
+	PrintStats()
+	Limit() dpll.Limit
+	Progress() float64
+}
+
+func runSolve(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	opt := optFlags(fs)
+	sopt := simpOptFlags(fs)
+	pre := fs.Bool("pre", true, "simplify the problem by variable elimination before search")
+	threads := fs.Int("threads", 1, "number of solvers run in parallel with different seeds, sharing short learnt clauses (disables -pre)")
+	shareMaxLen := fs.Int("share-max-len", 8, "longest learnt clause shared between parallel solvers (0 for no limit)")
+	c := commonFlags(fs)
+	path := c.parse(fs, args)
+	setProcs(*threads)
+
+	res := &cmdutil.Result{}
+	stopProfile, err := c.startProfile()
+	if err != nil {
+		return c.fail(res, err)
+	}
+	defer stopProfile()
+
+	if *threads > 1 {
+		popt := portfolioOpt{n: *threads, maxLen: *shareMaxLen, randVarFreq: portfolioRandVarFreq(fs, opt)}
+		return solvePortfolio(c, res, path, opt, popt)
+	}
+
+	var s solver
+	var d *dpll.DPLL
+	var simp *dpll.Simp
+	var stats func() interface{}
+	if *pre {
+		simp = newSimp(opt, sopt)
+		s = simp
+		stats = func() interface{} { return simp.Stats() }
+	} else {
+		d = newDPLL(opt)
+		s = d
+		stats = func() interface{} { return d.Stats() }
+	}
+
+	parseStart := time.Now()
+	err = c.decode(s, path)
+	if err != nil {
+		return c.fail(res, err)
+	}
+	parseEnd := time.Now()
+	res.Time.Parse = parseEnd.Sub(parseStart).Seconds()
+	logProblem(opt.Verbosity, s.NumVar(), s.NumClause(), parseEnd.Sub(parseStart))
+
+	c.setBudgets(s)
+	stop := c.watch(s.Interrupt)
+	defer stop()
+
+	var ok bool
+	if simp != nil {
+		ok = simp.Eliminate(true)
+	} else {
+		ok = d.Simplify()
+	}
+	simplifyEnd := time.Now()
+	res.Time.Simplify = simplifyEnd.Sub(parseEnd).Seconds()
+	if opt.Verbosity >= 1 {
+		dur := simplifyEnd.Sub(parseEnd)
+		log.Printf("|  Simplify time:        %12v                                         |", dur-dur%time.Microsecond)
+		log.Printf("|                                                                             |")
+	}
+	if !ok {
+		if opt.Verbosity >= 1 {
+			log.Printf("===============================================================================")
+			log.Printf("Solved by simplification")
+			s.PrintStats()
+			log.Println()
+		}
+		res.SetSolution(dpll.NewSolution(dpll.LFalse, nil), stats())
+		return c.exit(res, UNSAT)
+	}
+
+	status := s.SolveLimited()
+	res.Time.Search = time.Since(simplifyEnd).Seconds()
+	limit := dpll.NoLimit
+	if status.IsUndef() {
+		limit = s.Limit()
+	}
+	if opt.Verbosity >= 1 || limit != dpll.NoLimit {
+		s.PrintStats()
+		fmt.Fprintln(os.Stderr)
+	}
+	var model []dpll.LBool
+	if status.IsTrue() {
+		model = s.Model()
+	}
+	res.SetSolution(dpll.NewSolution(status, model), stats())
+	if limit != dpll.NoLimit {
+		progress := s.Progress()
+		res.SetLimit(limit.String(), &progress)
+	}
+	return c.exit(res, statusCode(status))
+}
+
+// logProblem logs the size of a problem and the time taken to parse it.
+func logProblem(verbosity, numVar, numClause int, parse time.Duration) {
+	log.Printf("============================[ Problem Statistics ]=============================")
+	if verbosity >= 1 {
+		log.Printf("|  Number of variables:  %12d                                         |", numVar)
+		log.Printf("|  Number of clauses:    %12d                                         |", numClause)
+		log.Printf("|  Parse time:           %12v                                         |", parse-parse%time.Microsecond)
+	}
+}
+
+// statusCode returns the exit code for the result of a search.
+func statusCode(status dpll.LBool) int {
+	switch {
+	case status.IsTrue():
+		return SAT
+	case status.IsFalse():
+		return UNSAT
+	}
+	return UNKNOWN
+}
+
+// solvePortfolio solves the problem at path with a portfolio of solvers.
+func solvePortfolio(c *common, res *cmdutil.Result, path string, opt *dpll.Opt, popt portfolioOpt) int {
+	parseStart := time.Now()
+	p, err := cmdutil.DecodeProblem(path, c.lenient)
+	if err != nil {
+		return c.fail(res, err)
+	}
+	pf, ok := newPortfolio(p, opt, popt)
+	for _, d := range pf.solvers {
+		c.setBudgets(d)
+	}
+	parseEnd := time.Now()
+	res.Time.Parse = parseEnd.Sub(parseStart).Seconds()
+	logProblem(opt.Verbosity, p.NumVar, len(p.Clauses), parseEnd.Sub(parseStart))
+	if !ok {
+		res.SetSolution(dpll.NewSolution(dpll.LFalse, nil), pf.solvers[0].Stats())
+		return c.exit(res, UNSAT)
+	}
+
+	stop := c.watch(pf.Interrupt)
+	defer stop()
+
+	d, status := pf.Solve()
+	res.Time.Search = time.Since(parseEnd).Seconds()
+	limit := dpll.NoLimit
+	if status.IsUndef() {
+		limit = d.Limit()
+	}
+	if opt.Verbosity >= 1 || limit != dpll.NoLimit {
+		d.PrintStats()
+		fmt.Fprintln(os.Stderr)
+	}
+	var model []dpll.LBool
+	if status.IsTrue() {
+		model = d.Model()
+	}
+	res.SetSolution(dpll.NewSolution(status, model), d.Stats())
+	if limit != dpll.NoLimit {
+		progress := d.Progress()
+		res.SetLimit(limit.String(), &progress)
+	}
+	return c.exit(res, statusCode(status))
+}
@@ -0,0 +1,178 @@
+/*
+Package cmdutil holds the input and output code shared by the dpll, dpll-go
+and dpll-simp commands, so that their results are written in the same
+formats.
+*/
+package cmdutil
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// Result is the output of a command.  The json format includes every field
+// while the text format is written by Text.
+type Result struct {
+	Status   string       `json:"status"`
+	Error    string       `json:"error,omitempty"`
+	Model    []dimacs.Lit `json:"model,omitempty"`
+	Cost     *int64       `json:"cost,omitempty"`
+	Count    string       `json:"count,omitempty"`
+	Limit    string       `json:"limit,omitempty"`    // the limit which stopped the search
+	Progress *float64     `json:"progress,omitempty"` // estimate of the search space explored
+	Stats    interface{}  `json:"stats,omitempty"`
+	Time     struct {
+		Parse    float64 `json:"parse"`
+		Simplify float64 `json:"simplify"`
+		Search   float64 `json:"search"`
+	} `json:"time"`
+
+	// Text writes the text format.  Nothing is written in the text format
+	// if Text is nil.
+	Text func(w io.Writer) error `json:"-"`
+}
+
+// SetSolution sets the status and model of r to those of sol, which is also
+// written in the text format.
+func (r *Result) SetSolution(sol *dimacs.Solution, stats interface{}) {
+	r.Status = sol.Status.String()
+	r.Model = sol.Model
+	r.Stats = stats
+	r.Text = func(w io.Writer) error {
+		return dimacs.EncodeSolution(w, sol)
+	}
+}
+
+// SetLimit records in r that the search was stopped by limit before it
+// completed, with the estimated progress of the search if known.  The text
+// format begins with comment lines describing the limit.  SetLimit must be
+// called after the status of r is set.
+func (r *Result) SetLimit(limit string, progress *float64) {
+	r.Limit = limit
+	r.Progress = progress
+	if progress != nil {
+		log.Printf("search stopped by the %s after exploring an estimated %.2f %% of the search space", limit, *progress*100)
+	} else {
+		log.Printf("search stopped by the %s", limit)
+	}
+	text := r.Text
+	r.Text = func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		fmt.Fprintf(bw, "c search stopped by the %s\n", limit)
+		if progress != nil {
+			fmt.Fprintf(bw, "c progress %.2f %%\n", *progress*100)
+		}
+		err := bw.Flush()
+		if err != nil || text == nil {
+			return err
+		}
+		return text(w)
+	}
+}
+
+// SetError records err in r.  Nothing is written in the text format.
+func (r *Result) SetError(err error) {
+	r.Status = "ERROR"
+	r.Error = err.Error()
+	r.Text = nil
+}
+
+// Write writes r in format, "text" or "json", to the file at path, or to
+// stdout if path is empty.  The file is compressed as described by
+// dimacs.Create.
+func (r *Result) Write(path, format string) error {
+	if format == "text" && r.Text == nil {
+		return nil
+	}
+	w := io.Writer(os.Stdout)
+	var f io.WriteCloser
+	if path != "" {
+		var err error
+		f, err = dimacs.Create(path)
+		if err != nil {
+			return err
+		}
+		w = f
+	}
+	var err error
+	if format == "json" {
+		err = json.NewEncoder(w).Encode(r)
+	} else {
+		err = r.Text(w)
+	}
+	if f != nil {
+		errc := f.Close()
+		if err == nil {
+			err = errc
+		}
+	}
+	return err
+}
+
+// OpenInput opens the file at path, or standard input if path is empty or
+// "-".  Compressed input is decompressed as described by dimacs.Open.
+func OpenInput(path string) (io.ReadCloser, error) {
+	if path == "" || path == "-" {
+		return dimacs.NewReader(os.Stdin)
+	}
+	return dimacs.Open(path)
+}
+
+// DecodeProblem decodes the CNF problem at path, which is opened by
+// OpenInput.
+func DecodeProblem(path string, lenient bool) (*dimacs.Problem, error) {
+	r, err := OpenInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	dec := dimacs.NewDecoder(r)
+	dec.Lenient = lenient
+	return dec.Problem()
+}
+
+// VerifySolution checks that the solution at solpath is a model of the
+// problem at path.
+func VerifySolution(path, solpath string, lenient bool) error {
+	p, err := DecodeProblem(path, lenient)
+	if err != nil {
+		return err
+	}
+	sol, err := dimacs.DecodeSolutionFile(solpath)
+	if err != nil {
+		return err
+	}
+	return sol.Check(p)
+}
+
+// WriteSimplified writes the simplified problem of s to the file at cnf and
+// its reconstruction to the file at rec, either of which may be empty.
+func WriteSimplified(s *dpll.Simp, cnf, rec string) error {
+	p, r := s.Simplified()
+	if cnf != "" {
+		err := dimacs.EncodeFile(cnf, p)
+		if err != nil {
+			return err
+		}
+	}
+	if rec == "" {
+		return nil
+	}
+	f, err := dimacs.Create(rec)
+	if err != nil {
+		return err
+	}
+	err = r.Write(f)
+	errc := f.Close()
+	if err != nil {
+		return err
+	}
+	return errc
+}
@@ -0,0 +1,61 @@
+package cmdutil
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func TestResult_Write(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cmdutil-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "result")
+
+	res := &Result{}
+	res.SetSolution(&dimacs.Solution{Status: dimacs.Unknown}, nil)
+	progress := 0.25
+	res.SetLimit("time budget", &progress)
+	err = res.Write(path, "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "c search stopped by the time budget\nc progress 25.00 %\ns UNKNOWN\n"
+	if string(b) != expect {
+		t.Errorf("text %q (expected %q)", b, expect)
+	}
+
+	err = res.Write(path, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = `{"status":"UNKNOWN","limit":"time budget","progress":0.25,"time":{"parse":0,"simplify":0,"search":0}}` + "\n"
+	if string(b) != expect {
+		t.Errorf("json %q (expected %q)", b, expect)
+	}
+
+	// errors are not written in the text format
+	os.Remove(path)
+	res.SetError(errors.New("failed"))
+	err = res.Write(path, "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("text written for an error: %v", err)
+	}
+}
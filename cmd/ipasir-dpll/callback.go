@@ -0,0 +1,55 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package main
+
+/*
+#include <stdlib.h>
+typedef int (*ipasir_terminate_fn)(void *data);
+typedef void (*ipasir_learn_fn)(void *data, int *clause);
+
+// Go cannot call C function pointers directly.  The definitions cannot be in
+// a file using //export.
+
+static int call_terminate(ipasir_terminate_fn fn, void *data) {
+	return fn(data);
+}
+
+static void call_learn(ipasir_learn_fn fn, void *data, int *clause) {
+	fn(data, clause);
+}
+*/
+import "C"
+
+import "unsafe"
+
+// terminateFunc returns a function calling the C terminate callback fn.
+func terminateFunc(fn C.ipasir_terminate_fn, data unsafe.Pointer) func() bool {
+	if fn == nil {
+		return nil
+	}
+	return func() bool {
+		return C.call_terminate(fn, data) != 0
+	}
+}
+
+// learnFunc returns a function calling the C learn callback fn with a zero
+// terminated copy of each clause.
+func learnFunc(fn C.ipasir_learn_fn, data unsafe.Pointer) func([]int) {
+	if fn == nil {
+		return nil
+	}
+	return func(clause []int) {
+		n := len(clause) + 1
+		p := (*C.int)(C.malloc(C.size_t(n) * C.size_t(unsafe.Sizeof(C.int(0)))))
+		defer C.free(unsafe.Pointer(p))
+		buf := unsafe.Slice(p, n)
+		for i, lit := range clause {
+			buf[i] = C.int(lit)
+		}
+		buf[n-1] = 0
+		C.call_learn(fn, data, p)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+/*
+Command ipasir-dpll builds a C library implementing the IPASIR incremental SAT
+solver interface using the dpll package.
+
+	go build -buildmode=c-shared -o libipasirdpll.so ./cmd/ipasir-dpll
+
+A static library can be built with -buildmode=c-archive.  Each solver returned
+by ipasir_init must be freed with ipasir_release.
+*/
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+typedef int (*ipasir_terminate_fn)(void *data);
+typedef void (*ipasir_learn_fn)(void *data, int *clause);
+*/
+import "C"
+
+import (
+	"runtime/cgo"
+	"unsafe"
+
+	"github.com/bmatsuo/dpll/ipasir"
+)
+
+// the signature must remain valid for the life of the program.
+var signature = C.CString(ipasir.New(nil).Signature())
+
+func main() {}
+
+// solver returns the *ipasir.Solver for a pointer returned by ipasir_init.
+// C code cannot hold Go pointers so the pointer refers to C memory holding a
+// cgo.Handle.
+func solver(p unsafe.Pointer) *ipasir.Solver {
+	return cgo.Handle(*(*C.uintptr_t)(p)).Value().(*ipasir.Solver)
+}
+
+//export ipasir_signature
+func ipasir_signature() *C.char {
+	return signature
+}
+
+//export ipasir_init
+func ipasir_init() unsafe.Pointer {
+	h := cgo.NewHandle(ipasir.NewDPLL(nil))
+	p := C.malloc(C.size_t(unsafe.Sizeof(C.uintptr_t(0))))
+	*(*C.uintptr_t)(p) = C.uintptr_t(h)
+	return p
+}
+
+//export ipasir_release
+func ipasir_release(p unsafe.Pointer) {
+	cgo.Handle(*(*C.uintptr_t)(p)).Delete()
+	C.free(p)
+}
+
+//export ipasir_add
+func ipasir_add(p unsafe.Pointer, lit C.int) {
+	solver(p).Add(int(lit))
+}
+
+//export ipasir_assume
+func ipasir_assume(p unsafe.Pointer, lit C.int) {
+	solver(p).Assume(int(lit))
+}
+
+//export ipasir_solve
+func ipasir_solve(p unsafe.Pointer) C.int {
+	return C.int(solver(p).Solve())
+}
+
+//export ipasir_val
+func ipasir_val(p unsafe.Pointer, lit C.int) C.int {
+	return C.int(solver(p).Val(int(lit)))
+}
+
+//export ipasir_failed
+func ipasir_failed(p unsafe.Pointer, lit C.int) C.int {
+	if solver(p).Failed(int(lit)) {
+		return 1
+	}
+	return 0
+}
+
+//export ipasir_set_terminate
+func ipasir_set_terminate(p unsafe.Pointer, data unsafe.Pointer, fn C.ipasir_terminate_fn) {
+	solver(p).SetTerminate(terminateFunc(fn, data))
+}
+
+//export ipasir_set_learn
+func ipasir_set_learn(p unsafe.Pointer, data unsafe.Pointer, maxLen C.int, fn C.ipasir_learn_fn) {
+	solver(p).SetLearn(int(maxLen), learnFunc(fn, data))
+}
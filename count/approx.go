@@ -0,0 +1,245 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package count
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+
+	"github.com/bmatsuo/dpll"
+)
+
+// ApproxOpt controls the accuracy of approximate counts.  With probability at
+// least 1-Delta the count returned by Approx is within a factor of 1+Epsilon
+// of the exact count.
+type ApproxOpt struct {
+	Epsilon float64 // Tolerance of the count
+	Delta   float64 // Probability that the count is outside the tolerance
+	Seed    int64   // Seed of the random hash functions
+}
+
+// DefaultApproxOpt is used by Approx for any zero fields of its argument.
+var DefaultApproxOpt = &ApproxOpt{
+	Epsilon: 0.8,
+	Delta:   0.2,
+	Seed:    1,
+}
+
+func mergeApproxOpt(o1, o2 *ApproxOpt) *ApproxOpt {
+	o := &ApproxOpt{}
+	*o = *o1
+	if o2 == nil {
+		return o
+	}
+	if o2.Epsilon != 0 {
+		o.Epsilon = o2.Epsilon
+	}
+	if o2.Delta != 0 {
+		o.Delta = o2.Delta
+	}
+	if o2.Seed != 0 {
+		o.Seed = o2.Seed
+	}
+	return o
+}
+
+// Approx returns an approximate number of models.  The models are partitioned
+// into cells by random XOR constraints over the counted variables, with more
+// constraints added until a cell holds fewer models than a threshold
+// determined by the tolerance.  The count is the median over several hash
+// functions of the size of a cell multiplied by the number of cells.  If the
+// problem has fewer models than the threshold the exact count is returned.
+func (c *Counter) Approx(opt *ApproxOpt) (*big.Int, error) {
+	opt = mergeApproxOpt(DefaultApproxOpt, opt)
+	if opt.Epsilon <= 0 {
+		panic("epsilon must be positive")
+	}
+	if opt.Delta <= 0 || opt.Delta >= 1 {
+		panic("delta must be between 0 and 1")
+	}
+
+	d := c.newSolver()
+	if d == nil {
+		return new(big.Int), nil
+	}
+	a := &approx{
+		Counter: c,
+		d:       d,
+		r:       rand.New(rand.NewSource(opt.Seed)),
+	}
+	for v := 1; v <= c.p.NumVar; v++ {
+		if c.isProjected(dpll.Var(v)) {
+			a.vars = append(a.vars, dpll.Var(v))
+		}
+	}
+
+	eps := opt.Epsilon
+	thresh := 1 + int(math.Ceil(9.84*(1+eps/(1+eps))*(1+1/eps)*(1+1/eps)))
+	n, err := a.cell(thresh)
+	if err != nil {
+		return nil, err
+	}
+	if n < thresh {
+		return big.NewInt(int64(n)), nil
+	}
+
+	iters := int(math.Ceil(17 * math.Log2(3/opt.Delta)))
+	var counts []*big.Int
+	for i := 0; i < iters; i++ {
+		m, err := a.estimate(thresh)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, m)
+	}
+	sort.Sort(bigSlice(counts))
+	return counts[len(counts)/2], nil
+}
+
+// approx holds the state of an approximate count.
+type approx struct {
+	*Counter
+	d    *dpll.DPLL
+	r    *rand.Rand
+	vars []dpll.Var // the counted variables
+	m    int        // hash constraints used by the last estimate
+}
+
+// estimate picks a random hash function and returns the size of a cell
+// multiplied by the number of cells, using the fewest hash constraints which
+// leave fewer than thresh models in the cell.  The search for the number of
+// constraints starts just below the number used by the previous estimate.
+func (a *approx) estimate(thresh int) (*big.Int, error) {
+	var hash []dpll.Group
+	var aux []dpll.Var
+	defer func() {
+		// auxiliary variables are released so the solver can reuse them
+		for _, g := range hash {
+			a.d.DeleteGroup(g)
+		}
+		for _, v := range aux {
+			a.d.ReleaseVar(dpll.Literal(v, false))
+		}
+	}()
+
+	m := a.m - 1
+	if m < 1 {
+		m = 1
+	}
+	for len(hash) < m {
+		g, vs := a.xor()
+		hash = append(hash, g)
+		aux = append(aux, vs...)
+	}
+	n, err := a.cell(thresh)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < thresh {
+		// fewer constraints may suffice
+		for m > 1 {
+			a.d.DisableGroup(hash[m-1])
+			k, err := a.cell(thresh)
+			if err != nil {
+				return nil, err
+			}
+			if k >= thresh {
+				break
+			}
+			m, n = m-1, k
+		}
+	} else {
+		// constraints may be dependent so there is no bound on their number
+		for n >= thresh {
+			g, vs := a.xor()
+			hash = append(hash, g)
+			aux = append(aux, vs...)
+			m++
+			n, err = a.cell(thresh)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	a.m = m
+	est := big.NewInt(int64(n))
+	return est.Lsh(est, uint(m)), nil
+}
+
+// xor adds a group containing a random XOR constraint over the counted
+// variables.  The constraint is encoded by a chain of auxiliary variables,
+// each the parity of a prefix of the constraint, which are returned.
+func (a *approx) xor() (g dpll.Group, aux []dpll.Var) {
+	g = a.d.NewGroup()
+	parity := dpll.LitUndef
+	for _, v := range a.vars {
+		if a.r.Intn(2) == 0 {
+			continue
+		}
+		x := dpll.Literal(v, false)
+		if parity == dpll.LitUndef {
+			parity = x
+			continue
+		}
+		t := dpll.Literal(a.d.NewVar(dpll.LUndef, true), false)
+		aux = append(aux, t.Var())
+		a.d.AddClauseToGroup(g, t.Inverse(), parity, x)
+		a.d.AddClauseToGroup(g, t.Inverse(), parity.Inverse(), x.Inverse())
+		a.d.AddClauseToGroup(g, t, parity.Inverse(), x)
+		a.d.AddClauseToGroup(g, t, parity, x.Inverse())
+		parity = t
+	}
+	odd := a.r.Intn(2) == 1
+	switch {
+	case parity == dpll.LitUndef && odd:
+		// the parity of no variables is even
+		a.d.AddClauseToGroup(g)
+	case parity == dpll.LitUndef:
+	case odd:
+		a.d.AddClauseToGroup(g, parity)
+	default:
+		a.d.AddClauseToGroup(g, parity.Inverse())
+	}
+	return g, aux
+}
+
+// cell returns the number of models projected onto the counted variables
+// under the enabled hash constraints, counting no further than thresh.
+func (a *approx) cell(thresh int) (int, error) {
+	g := a.d.NewGroup()
+	defer a.d.DeleteGroup(g)
+
+	var n int
+	for n < thresh {
+		switch status := a.d.SolveLimited(); {
+		case status.IsFalse():
+			return n, nil
+		case status.IsUndef():
+			return 0, ErrInterrupted
+		}
+		n++
+		if len(a.vars) == 0 {
+			break
+		}
+		model := a.d.Model()
+		block := make([]dpll.Lit, len(a.vars))
+		for i, v := range a.vars {
+			block[i] = dpll.Literal(v, model[v].IsTrue())
+		}
+		a.d.AddClauseToGroup(g, block...)
+	}
+	return n, nil
+}
+
+type bigSlice []*big.Int
+
+func (s bigSlice) Len() int           { return len(s) }
+func (s bigSlice) Less(i, j int) bool { return s[i].Cmp(s[j]) < 0 }
+func (s bigSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
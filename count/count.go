@@ -0,0 +1,372 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+/*
+Package count counts the models of CNF problems.  Exact counts are computed by
+a search which splits the problem into independent components and caches the
+count of each component.  Approximate counts are computed by the ApproxMC
+algorithm, which partitions the models with random XOR constraints.
+
+Counts may be projected onto a set of variables, in which case two models
+which differ only in variables outside the set are counted once.
+*/
+package count
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// ErrInterrupted is returned when counting is interrupted or the search
+// budget of the solver is exhausted.
+var ErrInterrupted = errors.New("count: interrupted")
+
+// Counter counts the models of a problem.
+type Counter struct {
+	p       *dimacs.Problem
+	project []bool // variables counted; nil if all variables are counted
+	opt     *dpll.Opt
+
+	mut         sync.Mutex
+	d           *dpll.DPLL // the solver in use
+	interrupted bool
+}
+
+// New returns a Counter for the models of p projected onto the variables in
+// project.  If project is nil all variables of p are counted.  The solvers
+// used in counting are created with opt.
+func New(p *dimacs.Problem, project []dpll.Var, opt *dpll.Opt) *Counter {
+	c := &Counter{p: p, opt: opt}
+	if project != nil {
+		c.project = make([]bool, p.NumVar+1)
+		for _, v := range project {
+			if int(v) > p.NumVar {
+				panic("projected variable is not in the problem")
+			}
+			c.project[v] = true
+		}
+	}
+	return c
+}
+
+// Interrupt stops counting from another goroutine.  The method counting
+// returns ErrInterrupted.
+func (c *Counter) Interrupt() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.interrupted = true
+	if c.d != nil {
+		c.d.Interrupt()
+	}
+}
+
+func (c *Counter) isProjected(v dpll.Var) bool {
+	return c.project == nil || c.project[v]
+}
+
+// newSolver creates a solver containing the clauses of the problem.  If the
+// problem is unsatisfiable newSolver returns nil.
+func (c *Counter) newSolver() *dpll.DPLL {
+	d := dpll.New(c.opt)
+	for d.NumVar() < c.p.NumVar {
+		d.NewVar(dpll.LUndef, true)
+	}
+	ok := true
+	for _, cl := range c.p.Clauses {
+		ok = ok && d.AddClause(literals(cl)...)
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.d = d
+	if c.interrupted {
+		d.Interrupt()
+	}
+	if !ok {
+		return nil
+	}
+	return d
+}
+
+func literals(c []dimacs.Lit) []dpll.Lit {
+	ps := make([]dpll.Lit, len(c))
+	for i, x := range c {
+		ps[i] = dpll.LiteralInt(int(x))
+	}
+	return ps
+}
+
+// Exact returns the exact number of models.
+func (c *Counter) Exact() (*big.Int, error) {
+	d := c.newSolver()
+	if d == nil {
+		return new(big.Int), nil
+	}
+	e := &exact{
+		Counter: c,
+		d:       d,
+		clauses: make([][]dpll.Lit, len(c.p.Clauses)),
+		cache:   make(map[string]*big.Int),
+		value:   make([]dpll.LBool, c.p.NumVar+1),
+	}
+	for i, cl := range c.p.Clauses {
+		e.clauses[i] = literals(cl)
+	}
+	for v := range e.value {
+		e.value[v] = dpll.LUndef
+	}
+
+	clauses := make([]int, len(e.clauses))
+	for i := range clauses {
+		clauses[i] = i
+	}
+	vars := make([]dpll.Var, c.p.NumVar)
+	for i := range vars {
+		vars[i] = dpll.Var(i + 1)
+	}
+	return e.count(nil, clauses, vars)
+}
+
+// exact holds the state of an exact count.
+type exact struct {
+	*Counter
+	d       *dpll.DPLL
+	clauses [][]dpll.Lit
+	cache   map[string]*big.Int
+	value   []dpll.LBool // assignments above the root level
+}
+
+// val returns the value of v under the current assumptions.
+func (e *exact) val(v dpll.Var) dpll.LBool {
+	if val := e.value[v]; !val.IsUndef() {
+		return val
+	}
+	return e.d.Value(v)
+}
+
+// component is a set of unassigned variables and the unsatisfied clauses
+// containing them.  Components share no variables so they may be counted
+// independently.
+type component struct {
+	vars    []dpll.Var
+	clauses []int
+}
+
+// key identifies the residual formula of a component.  The variables of a
+// component are exactly the unassigned variables of its clauses, so they
+// determine which literals remain in each clause.
+func (comp *component) key() string {
+	var buf bytes.Buffer
+	tmp := make([]byte, binary.MaxVarintLen64)
+	for _, v := range comp.vars {
+		buf.Write(tmp[:binary.PutUvarint(tmp, uint64(v))])
+	}
+	buf.WriteByte(0)
+	for _, i := range comp.clauses {
+		buf.Write(tmp[:binary.PutUvarint(tmp, uint64(i)+1)])
+	}
+	return buf.String()
+}
+
+// count returns the number of models of the given clauses over vars under
+// the assumptions assump.
+func (e *exact) count(assump []dpll.Lit, clauses []int, vars []dpll.Var) (*big.Int, error) {
+	// the solver prunes unsatisfiable branches, learning clauses which
+	// strengthen later propagation.
+	switch status := e.d.SolveLimited(assump...); {
+	case status.IsFalse():
+		return new(big.Int), nil
+	case status.IsUndef():
+		return nil, ErrInterrupted
+	}
+	implied, ok := e.d.Implies(assump)
+	if !ok {
+		return new(big.Int), nil
+	}
+
+	for _, ps := range [][]dpll.Lit{assump, implied} {
+		for _, p := range ps {
+			e.value[p.Var()] = dpll.LiftBool(!p.IsNeg())
+		}
+	}
+	comps, free := e.components(clauses, vars)
+	for _, ps := range [][]dpll.Lit{assump, implied} {
+		for _, p := range ps {
+			e.value[p.Var()] = dpll.LUndef
+		}
+	}
+
+	n := new(big.Int).Lsh(big.NewInt(1), uint(free))
+	for _, comp := range comps {
+		m, err := e.countComponent(assump, comp)
+		if err != nil {
+			return nil, err
+		}
+		n.Mul(n, m)
+		if n.Sign() == 0 {
+			break
+		}
+	}
+	return n, nil
+}
+
+// countComponent returns the number of models of comp under assump.
+func (e *exact) countComponent(assump []dpll.Lit, comp *component) (*big.Int, error) {
+	key := comp.key()
+	if n, ok := e.cache[key]; ok {
+		return n, nil
+	}
+
+	x, ok := e.branchVar(comp)
+	if !ok {
+		// no projected variables remain and the component is satisfiable
+		e.cache[key] = big.NewInt(1)
+		return e.cache[key], nil
+	}
+
+	vars := make([]dpll.Var, 0, len(comp.vars)-1)
+	for _, v := range comp.vars {
+		if v != x {
+			vars = append(vars, v)
+		}
+	}
+	n := new(big.Int)
+	for _, p := range []dpll.Lit{dpll.Literal(x, false), dpll.Literal(x, true)} {
+		branch := make([]dpll.Lit, len(assump), len(assump)+1)
+		copy(branch, assump)
+		m, err := e.count(append(branch, p), comp.clauses, vars)
+		if err != nil {
+			return nil, err
+		}
+		n.Add(n, m)
+	}
+	e.cache[key] = n
+	return n, nil
+}
+
+// branchVar returns the projected variable of comp occurring in the most
+// clauses.
+func (e *exact) branchVar(comp *component) (dpll.Var, bool) {
+	occ := make(map[dpll.Var]int)
+	for _, i := range comp.clauses {
+		for _, p := range e.clauses[i] {
+			if e.isProjected(p.Var()) {
+				occ[p.Var()]++
+			}
+		}
+	}
+	best, nbest := dpll.VarUndef, 0
+	for _, v := range comp.vars {
+		if occ[v] > nbest {
+			best, nbest = v, occ[v]
+		}
+	}
+	return best, nbest > 0
+}
+
+// components partitions the clauses unsatisfied under the current
+// assumptions into components.  The number of unassigned projected variables
+// in vars which occur in no unsatisfied clause is also returned.
+func (e *exact) components(clauses []int, vars []dpll.Var) (comps []*component, free int) {
+	uf := newUnionFind()
+	var open []int
+nextClause:
+	for _, i := range clauses {
+		first := dpll.VarUndef
+		for _, p := range e.clauses[i] {
+			if e.val(p.Var()).Xor(p.IsNeg()).IsTrue() {
+				continue nextClause
+			}
+		}
+		for _, p := range e.clauses[i] {
+			if e.val(p.Var()).IsUndef() {
+				if first.IsUndef() {
+					first = p.Var()
+				}
+				uf.union(first, p.Var())
+			}
+		}
+		open = append(open, i)
+	}
+
+	byRoot := make(map[dpll.Var]*component)
+	for _, v := range vars {
+		if !e.val(v).IsUndef() {
+			continue
+		}
+		if !uf.contains(v) {
+			if e.isProjected(v) {
+				free++
+			}
+			continue
+		}
+		r := uf.find(v)
+		comp, ok := byRoot[r]
+		if !ok {
+			comp = &component{}
+			byRoot[r] = comp
+			comps = append(comps, comp)
+		}
+		comp.vars = append(comp.vars, v)
+	}
+	for _, i := range open {
+		for _, p := range e.clauses[i] {
+			if e.val(p.Var()).IsUndef() {
+				comp := byRoot[uf.find(p.Var())]
+				comp.clauses = append(comp.clauses, i)
+				break
+			}
+		}
+	}
+	for _, comp := range comps {
+		sort.Sort(varSlice(comp.vars))
+	}
+	return comps, free
+}
+
+type unionFind map[dpll.Var]dpll.Var
+
+func newUnionFind() unionFind {
+	return make(unionFind)
+}
+
+func (uf unionFind) contains(v dpll.Var) bool {
+	_, ok := uf[v]
+	return ok
+}
+
+func (uf unionFind) find(v dpll.Var) dpll.Var {
+	p, ok := uf[v]
+	if !ok {
+		uf[v] = v
+		return v
+	}
+	if p == v {
+		return v
+	}
+	r := uf.find(p)
+	uf[v] = r
+	return r
+}
+
+func (uf unionFind) union(v, w dpll.Var) {
+	rv, rw := uf.find(v), uf.find(w)
+	if rv != rw {
+		uf[rv] = rw
+	}
+}
+
+type varSlice []dpll.Var
+
+func (s varSlice) Len() int           { return len(s) }
+func (s varSlice) Less(i, j int) bool { return s[i] < s[j] }
+func (s varSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,174 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package count
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func randomProblem(seed int64, nvar, nclause, width int) *dimacs.Problem {
+	r := rand.New(rand.NewSource(seed))
+	p := &dimacs.Problem{NumVar: nvar}
+	for len(p.Clauses) < nclause {
+		var c []dimacs.Lit
+	nextLit:
+		for len(c) < width {
+			x := dimacs.Lit(r.Intn(nvar) + 1)
+			for _, y := range c {
+				if y.Var() == x.Var() {
+					continue nextLit
+				}
+			}
+			if r.Intn(2) == 0 {
+				x = -x
+			}
+			c = append(c, x)
+		}
+		p.Clauses = append(p.Clauses, c)
+	}
+	return p
+}
+
+// bruteCount enumerates the assignments of p and counts the distinct
+// restrictions of its models to project.
+func bruteCount(p *dimacs.Problem, project []dpll.Var) int64 {
+	seen := make(map[uint64]bool)
+	for x := uint64(0); x < 1<<uint(p.NumVar); x++ {
+		value := func(v int) bool { return x&(1<<uint(v-1)) != 0 }
+		sat := true
+		for _, c := range p.Clauses {
+			csat := false
+			for _, y := range c {
+				if value(int(y.Var())) != (y < 0) {
+					csat = true
+					break
+				}
+			}
+			if !csat {
+				sat = false
+				break
+			}
+		}
+		if !sat {
+			continue
+		}
+		key := x
+		if project != nil {
+			key = 0
+			for _, v := range project {
+				if value(int(v)) {
+					key |= 1 << uint(v-1)
+				}
+			}
+		}
+		seen[key] = true
+	}
+	return int64(len(seen))
+}
+
+func randomProject(seed int64, nvar int) []dpll.Var {
+	r := rand.New(rand.NewSource(seed))
+	var project []dpll.Var
+	for v := 1; v <= nvar; v++ {
+		if r.Intn(2) == 0 {
+			project = append(project, dpll.Var(v))
+		}
+	}
+	return project
+}
+
+func TestCounter_Exact(t *testing.T) {
+	for seed := int64(1); seed < 40; seed++ {
+		p := randomProblem(seed, 14, 20+int(seed%5)*8, 3)
+		for _, project := range [][]dpll.Var{nil, randomProject(seed, p.NumVar), {}} {
+			n, err := New(p, project, nil).Exact()
+			if err != nil {
+				t.Fatalf("seed %d: %v", seed, err)
+			}
+			if expect := bruteCount(p, project); n.Cmp(big.NewInt(expect)) != 0 {
+				t.Errorf("seed %d project %v: count %v (expected %d)", seed, project, n, expect)
+			}
+		}
+	}
+}
+
+func TestCounter_Exact_unsat(t *testing.T) {
+	p := &dimacs.Problem{
+		NumVar:  2,
+		Clauses: [][]dimacs.Lit{{1, 2}, {-1}, {-2}},
+	}
+	n, err := New(p, nil, nil).Exact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Sign() != 0 {
+		t.Errorf("count: %v", n)
+	}
+}
+
+func TestCounter_Exact_big(t *testing.T) {
+	// unconstrained variables double the count beyond the range of int64
+	p := &dimacs.Problem{
+		NumVar:  100,
+		Clauses: [][]dimacs.Lit{{1, 2}},
+	}
+	n, err := New(p, nil, nil).Exact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := new(big.Int).Lsh(big.NewInt(3), 98)
+	if n.Cmp(expect) != 0 {
+		t.Errorf("count: %v (expected %v)", n, expect)
+	}
+}
+
+func TestCounter_Approx(t *testing.T) {
+	opt := &ApproxOpt{Epsilon: 0.8, Delta: 0.2}
+	for seed := int64(1); seed < 4; seed++ {
+		p := randomProblem(seed, 14, 10, 3)
+		for _, project := range [][]dpll.Var{nil, randomProject(seed, p.NumVar)} {
+			opt.Seed = seed
+			n, err := New(p, project, nil).Approx(opt)
+			if err != nil {
+				t.Fatalf("seed %d: %v", seed, err)
+			}
+			expect := float64(bruteCount(p, project))
+			approx, _ := new(big.Float).SetInt(n).Float64()
+			if approx < expect/(1+opt.Epsilon) || approx > expect*(1+opt.Epsilon) {
+				t.Errorf("seed %d project %v: count %v (expected %v)", seed, project, n, expect)
+			}
+		}
+	}
+}
+
+func TestCounter_Approx_small(t *testing.T) {
+	// problems with few models are counted exactly
+	p := randomProblem(3, 10, 30, 3)
+	project := randomProject(3, p.NumVar)
+	n, err := New(p, project, nil).Approx(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := bruteCount(p, project); n.Cmp(big.NewInt(expect)) != 0 {
+		t.Errorf("count: %v (expected %d)", n, expect)
+	}
+}
+
+func TestCounter_Interrupt(t *testing.T) {
+	c := New(randomProblem(1, 30, 60, 3), nil, nil)
+	c.Interrupt()
+	if _, err := c.Exact(); err != ErrInterrupted {
+		t.Errorf("exact: %v", err)
+	}
+	if _, err := c.Approx(nil); err != ErrInterrupted {
+		t.Errorf("approx: %v", err)
+	}
+}
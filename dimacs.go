@@ -7,7 +7,6 @@ package dpll
 
 import (
 	"io"
-	"os"
 
 	"github.com/bmatsuo/dpll/dimacs"
 )
@@ -27,7 +26,8 @@ type Solver interface {
 }
 
 // DecodeFile decodes a CNF problem in DIMACS format from a file at the given
-// path and adds the contained clauses into s.
+// path and adds the contained clauses into s.  Compressed files are
+// decompressed as described by dimacs.Open.
 //
 //		solver := dpll.New(nil)
 //		err := DecodeFile(solver, "problem.dimacs")
@@ -35,20 +35,49 @@ type Solver interface {
 //			// ...
 //		}
 func DecodeFile(s Solver, path string) (ok bool, err error) {
-	f, err := os.Open(path)
+	return decodeFile(s, path, false)
+}
+
+// DecodeFileLenient is like DecodeFile but decodes the file leniently, as
+// described by DecodeLenient.
+func DecodeFileLenient(s Solver, path string) (ok bool, err error) {
+	return decodeFile(s, path, true)
+}
+
+func decodeFile(s Solver, path string, lenient bool) (ok bool, err error) {
+	f, err := dimacs.Open(path)
 	if err != nil {
 		return false, err
 	}
 	defer f.Close()
-	return Decode(s, f)
+	return decode(s, f, lenient)
 }
 
 // Decode is like DecodeFile. But, Decode reads a DIMACS formatted byte stream
-// from r.
+// from r.  Each clause must occupy a single line and the number of clauses
+// must match the header.  Malformed input is reported with a *dimacs.Error,
+// as is a quantified problem in QDIMACS format or an incremental problem in
+// iCNF format.  Incremental problems are decoded by dimacs.DecodeIncProblem
+// and solved by Replay.
 func Decode(s Solver, r io.Reader) (ok bool, err error) {
+	return decode(s, r, false)
+}
+
+// DecodeLenient is like Decode but clauses may span lines and the number of
+// clauses need not match the header, as described by dimacs.Decoder.
+func DecodeLenient(s Solver, r io.Reader) (ok bool, err error) {
+	return decode(s, r, true)
+}
+
+func decode(s Solver, r io.Reader, lenient bool) (ok bool, err error) {
 	dec := dimacs.NewDecoder(r)
+	dec.Lenient = lenient
 	var ps []Lit
 	for dec.Decode() {
+		err = dec.CheckCNF()
+		if err != nil {
+			return false, err
+		}
 		dc := dec.Clause()
 		if len(ps) < len(dc) {
 			ps = make([]Lit, 2*len(dc))
@@ -56,13 +85,7 @@ func Decode(s Solver, r io.Reader) (ok bool, err error) {
 			ps = ps[:len(dc)]
 		}
 		for i, dl := range dc {
-			if dl < 0 {
-				checkVar(uint(-dl))
-				ps[i] = Literal(Var(-dl), true)
-			} else {
-				checkVar(uint(dl))
-				ps[i] = Literal(Var(dl), false)
-			}
+			ps[i] = LiteralInt(int(dl))
 		}
 		for _, p := range ps {
 			for p.Var() > Var(s.NumVar()) {
@@ -79,5 +102,31 @@ func Decode(s Solver, r io.Reader) (ok bool, err error) {
 	if dec.Err() != nil {
 		return false, dec.Err()
 	}
+	err = dec.CheckCNF()
+	if err != nil {
+		return false, err
+	}
 	return true, nil
 }
+
+// NewSolution returns a solution in the format of the SAT competitions for
+// the status and model returned by a solver.  The model is ignored unless
+// status is LTrue.
+func NewSolution(status LBool, model []LBool) *dimacs.Solution {
+	sol := &dimacs.Solution{}
+	switch {
+	case status.IsTrue():
+		sol.Status = dimacs.Satisfiable
+		for v := 1; v < len(model); v++ {
+			switch {
+			case model[v].IsTrue():
+				sol.Model = append(sol.Model, dimacs.Lit(v))
+			case model[v].IsFalse():
+				sol.Model = append(sol.Model, dimacs.Lit(-v))
+			}
+		}
+	case status.IsFalse():
+		sol.Status = dimacs.Unsatisfiable
+	}
+	return sol
+}
@@ -0,0 +1,200 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// compression is a format for compressed files.  A format without a reader
+// is known by its magic bytes and extension but its package has not been
+// registered, see RegisterCompression.
+type compression struct {
+	name      string
+	ext       string
+	magic     []byte
+	newReader func(io.Reader) (io.ReadCloser, error)
+	newWriter func(io.Writer) (io.WriteCloser, error)
+}
+
+var (
+	compressionMu sync.Mutex
+
+	// compressions are the known formats.  The xz and zstd formats are
+	// registered by package github.com/bmatsuo/dpll/dimacs/xzstd, so that
+	// programs which do not import it need only the standard library.
+	compressions = []*compression{
+		{
+			name:  "gzip",
+			ext:   ".gz",
+			magic: []byte{0x1f, 0x8b},
+			newReader: func(r io.Reader) (io.ReadCloser, error) {
+				return gzip.NewReader(r)
+			},
+			newWriter: func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(w), nil
+			},
+		},
+		{
+			// the standard library can only decompress bzip2
+			name:  "bzip2",
+			ext:   ".bz2",
+			magic: []byte("BZh"),
+			newReader: func(r io.Reader) (io.ReadCloser, error) {
+				return io.NopCloser(bzip2.NewReader(r)), nil
+			},
+		},
+		{name: "xz", ext: ".xz", magic: []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+		{name: "zstd", ext: ".zst", magic: []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	}
+)
+
+// RegisterCompression registers a compressed format for Open, NewReader and
+// Create.  Input beginning with magic is decompressed by newReader, and files
+// created with the extension ext are compressed by newWriter, which may be nil
+// if the format is only supported for input.  A format registered with the
+// name of a known format replaces it.  RegisterCompression is typically called
+// by the init function of the package providing the format.
+func RegisterCompression(name, ext string, magic []byte, newReader func(io.Reader) (io.ReadCloser, error), newWriter func(io.Writer) (io.WriteCloser, error)) {
+	c := &compression{name, ext, magic, newReader, newWriter}
+	compressionMu.Lock()
+	defer compressionMu.Unlock()
+	for i, c2 := range compressions {
+		if c2.name == name {
+			compressions[i] = c
+			return
+		}
+	}
+	compressions = append(compressions, c)
+}
+
+// lookupCompression returns the first compression for which match returns
+// true, or nil if there is none.
+func lookupCompression(match func(c *compression) bool) *compression {
+	compressionMu.Lock()
+	defer compressionMu.Unlock()
+	for _, c := range compressions {
+		if match(c) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Open opens the file at path for reading.  If the file is compressed with
+// gzip or bzip2, as determined by its first bytes, reads from the returned
+// file are decompressed.  Files compressed with xz or zstd are decompressed
+// once package github.com/bmatsuo/dpll/dimacs/xzstd is imported.  Other
+// formats may be added with RegisterCompression.
+func Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &readCloser{r, f}, nil
+}
+
+// NewReader returns a reader which decompresses r if its contents are
+// compressed in a format recognized by Open.  Closing the returned reader
+// releases resources held for decompression but does not close r.
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	c := detect(br)
+	switch {
+	case c == nil:
+		return io.NopCloser(br), nil
+	case c.newReader == nil:
+		return nil, errUnregistered(c)
+	}
+	return c.newReader(br)
+}
+
+// detect returns the compression of the data buffered in r, or nil if the
+// data is not compressed.
+func detect(r *bufio.Reader) *compression {
+	return lookupCompression(func(c *compression) bool {
+		b, _ := r.Peek(len(c.magic))
+		return bytes.Equal(b, c.magic)
+	})
+}
+
+func errUnregistered(c *compression) error {
+	return fmt.Errorf("dimacs: %s compression requires package github.com/bmatsuo/dpll/dimacs/xzstd", c.name)
+}
+
+// Create creates the file at path for writing.  If path has the extension
+// .gz, writes to the returned file are compressed with gzip.  The extensions
+// .xz and .zst select xz and zstd compression once package
+// github.com/bmatsuo/dpll/dimacs/xzstd is imported.  Paths with the extension
+// of a format which is only supported for input, such as .bz2, are rejected.
+// The returned file must be closed for its contents to be completely written.
+func Create(path string) (io.WriteCloser, error) {
+	ext := filepath.Ext(path)
+	c := lookupCompression(func(c *compression) bool { return c.ext == ext })
+	switch {
+	case c == nil:
+	case c.newReader == nil:
+		return nil, errUnregistered(c)
+	case c.newWriter == nil:
+		return nil, fmt.Errorf("dimacs: %s compression is not supported for output", c.name)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return f, nil
+	}
+	w, err := c.newWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &writeCloser{w, f}, nil
+}
+
+// readCloser reads from a decompressor and closes the underlying file.
+type readCloser struct {
+	io.ReadCloser
+	f *os.File
+}
+
+func (r *readCloser) Close() error {
+	err := r.ReadCloser.Close()
+	errf := r.f.Close()
+	if err != nil {
+		return err
+	}
+	return errf
+}
+
+// writeCloser writes to a compressor and closes the underlying file.
+type writeCloser struct {
+	io.WriteCloser
+	f *os.File
+}
+
+func (w *writeCloser) Close() error {
+	err := w.WriteCloser.Close()
+	errf := w.f.Close()
+	if err != nil {
+		return err
+	}
+	return errf
+}
@@ -0,0 +1,132 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeFile_compressed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dimacs-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &Problem{
+		NumVar:  3,
+		Clauses: [][]Lit{{1, -2}, {2, 3}, {-1, -3}},
+	}
+	var plain bytes.Buffer
+	err = EncodeProblem(&plain, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ext := range []string{"", ".gz"} {
+		path := filepath.Join(dir, "problem.cnf"+ext)
+		err := EncodeFile(path, p)
+		if err != nil {
+			t.Errorf("%q: %v", ext, err)
+			continue
+		}
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if compressed := !bytes.Equal(raw, plain.Bytes()); compressed != (ext != "") {
+			t.Errorf("%q: compressed %v", ext, compressed)
+		}
+
+		// compression is detected by content rather than by name
+		renamed := filepath.Join(dir, "problem"+ext+".txt")
+		err = os.Rename(path, renamed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p2, err := DecodeFile(renamed)
+		if err != nil {
+			t.Errorf("%q: %v", ext, err)
+			continue
+		}
+		if !reflect.DeepEqual(p2, p) {
+			t.Errorf("%q: decoded %v (expected %v)", ext, p2, p)
+		}
+	}
+}
+
+func TestCreate_unsupported(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dimacs-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// bzip2 is only supported for input and xz and zstd are not registered
+	// unless package xzstd is imported.
+	for _, ext := range []string{".bz2", ".xz", ".zst"} {
+		path := filepath.Join(dir, "problem.cnf"+ext)
+		_, err = Create(path)
+		if err == nil {
+			t.Errorf("%q: no error", ext)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%q: file created: %v", ext, err)
+		}
+	}
+}
+
+func TestNewReader_unregistered(t *testing.T) {
+	xz := []byte{0xfd, '7', 'z', 'X', 'Z', 0x00, 0x00}
+	_, err := NewReader(bytes.NewReader(xz))
+	if err == nil || !strings.Contains(err.Error(), "xzstd") {
+		t.Errorf("error %v", err)
+	}
+}
+
+func TestNewReader_bzip2(t *testing.T) {
+	// bzip2 -c of "p cnf 3 3\n1 -2 0\n2 3 0\n-1 -3 0\n"
+	bz := []byte{
+		0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x58, 0xa4,
+		0xe8, 0xc1, 0x00, 0x00, 0x0f, 0x59, 0x80, 0x00, 0x10, 0x40, 0x02, 0x78,
+		0x00, 0x09, 0x01, 0x40, 0x00, 0x20, 0x00, 0x31, 0x00, 0xd3, 0x4d, 0x02,
+		0x54, 0xf2, 0x8c, 0x9a, 0x7a, 0x9b, 0xb4, 0x50, 0xed, 0x0b, 0x25, 0xf7,
+		0x42, 0xc1, 0x31, 0xa2, 0x4e, 0x7c, 0x5d, 0xc9, 0x14, 0xe1, 0x42, 0x41,
+		0x62, 0x93, 0xa3, 0x04,
+	}
+	r, err := NewReader(bytes.NewReader(bz))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := "p cnf 3 3\n1 -2 0\n2 3 0\n-1 -3 0\n"; string(b) != s {
+		t.Errorf("read %q (expected %q)", b, s)
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	// short uncompressed input is not mistaken for compressed input
+	for _, s := range []string{"", "p", "p cnf 0 0\n"} {
+		r, err := NewReader(bytes.NewBufferString(s))
+		if err != nil {
+			t.Errorf("%q: %v", s, err)
+			continue
+		}
+		b, err := ioutil.ReadAll(r)
+		if err != nil || string(b) != s {
+			t.Errorf("%q: read %q %v", s, b, err)
+		}
+	}
+}
@@ -7,35 +7,84 @@ package dimacs
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
-	"strings"
-	"unicode"
-	"unsafe"
 )
 
-// Decoder reads a DIMACS format stream of bytes from an io.Decoder.
+// Decoder reads a DIMACS format stream of bytes from an io.Reader.  Input is
+// tokenized byte by byte so there is no limit on the length of a line.
+//
+// By default a Decoder is strict.  Each clause must occupy a single line and
+// the number of clauses may not exceed the count given in the header.
+//
+// A Decoder also reads the incremental iCNF format, which has the header
+// "p inccnf" and interleaves clauses with assumption lines beginning with 'a'.
+// Each assumption line requests a solve under its literals and is returned by
+// Decode like a clause, with Assumption reporting true.
 type Decoder struct {
-	s     *bufio.Scanner
+	// Lenient allows clauses which span lines or share a line with other
+	// clauses, a number of clauses differing from the header, a final clause
+	// without a terminating null, and a line beginning with '%' to end the
+	// input as it does in some benchmark suites.  Lenient must be set before
+	// the input is decoded.
+	Lenient bool
+
+	r       *bufio.Reader
+	line    int // position of the next input byte
+	col     int
+	tokLine int // position of the last token read
+	tokCol  int
+	cline   int // position of the last clause decoded
+	ccol    int
+	qline   int // position of the first quantifier line
+	qcol    int
+	hline   int // position of the header
+	hcol    int
+	done    bool
+	assump  bool // the last line decoded was an assumption line
+	buf     []byte
+
 	h     *Header
-	htext []byte
 	n     int
 	c     []Lit
+	q     []QuantBlock
+	qseen []bool
 	err   error
 }
 
-// NewDecoder returns
+// NewDecoder returns a strict Decoder reading from r.
 func NewDecoder(r io.Reader) *Decoder {
-	return &Decoder{s: bufio.NewScanner(r)}
+	return &Decoder{
+		r:    bufio.NewReader(r),
+		line: 1,
+		col:  1,
+	}
 }
 
 // Err returns any error that encountered while decoding the input bytes.
+// Malformed input is reported with an *Error.
 func (r *Decoder) Err() error {
 	return r.err
 }
 
+func (r *Decoder) errorf(kind ErrorKind, line, col int, tok string, format string, v ...interface{}) {
+	r.err = &Error{
+		Kind:   kind,
+		Line:   line,
+		Column: col,
+		Token:  tok,
+		Msg:    fmt.Sprintf(format, v...),
+	}
+}
+
+// Position returns the line and column at which the last clause decoded
+// began.
+func (r *Decoder) Position() (line, column int) {
+	return r.cline, r.ccol
+}
+
 // Header returns the header decoded from the input stream.  Header returns nil
 // if no header could be decoded from the input.  If Header returns nil then
 // r.Err() will return the encountered error.
@@ -51,86 +100,180 @@ func (r *Decoder) Header() *Header {
 	return h
 }
 
-func (r *Decoder) skipComments() (ok bool) {
-	for r.s.Scan() {
-		b := r.s.Bytes()
-		if len(b) == 0 {
-			return
+// peekByte returns the next byte of input without consuming it.  At the end
+// of input or after a read error peekByte returns false.
+func (r *Decoder) peekByte() (byte, bool) {
+	b, err := r.r.Peek(1)
+	if err != nil {
+		if err != io.EOF {
+			r.err = err
 		}
-		if b[0] == 'c' {
-			continue
-		}
-		r.htext = b
+		return 0, false
+	}
+	return b[0], true
+}
+
+// skipByte consumes the byte returned by peekByte.
+func (r *Decoder) skipByte() {
+	c, _ := r.r.ReadByte()
+	if c == '\n' {
+		r.line++
+		r.col = 1
+	} else {
+		r.col++
+	}
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\v', '\f':
 		return true
 	}
-	r.err = r.s.Err()
 	return false
 }
 
+// skipSpace consumes whitespace and returns true if it contained a newline.
+func (r *Decoder) skipSpace() (newline bool) {
+	for {
+		c, ok := r.peekByte()
+		if !ok || !isSpace(c) {
+			return newline
+		}
+		newline = newline || c == '\n'
+		r.skipByte()
+	}
+}
+
+// readLine consumes the remainder of the current line, including the newline,
+// and returns it without line terminators.  The returned slice is valid until
+// the next call to readLine.
+func (r *Decoder) readLine() []byte {
+	r.buf = r.buf[:0]
+	for {
+		c, ok := r.peekByte()
+		if !ok {
+			break
+		}
+		r.skipByte()
+		if c == '\n' {
+			break
+		}
+		r.buf = append(r.buf, c)
+	}
+	if n := len(r.buf); n > 0 && r.buf[n-1] == '\r' {
+		r.buf = r.buf[:n-1]
+	}
+	return r.buf
+}
+
+// field is a whitespace delimited field of a line and its column.
+type field struct {
+	text string
+	col  int
+}
+
+// fields splits line, which begins at column col, into fields.
+func fields(line []byte, col int) []field {
+	var fs []field
+	for i := 0; i < len(line); {
+		if isSpace(line[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(line) && !isSpace(line[j]) {
+			j++
+		}
+		fs = append(fs, field{string(line[i:j]), col + i})
+		i = j
+	}
+	return fs
+}
+
 func (r *Decoder) readHeader() {
 	if r.h != nil || r.err != nil {
 		return
 	}
 
-	if !r.skipComments() && r.err != nil {
-		return
+	var c byte
+	for {
+		r.skipSpace()
+		var ok bool
+		c, ok = r.peekByte()
+		if !ok {
+			if r.err == nil {
+				r.errorf(ErrHeader, r.line, r.col, "", "missing problem header")
+			}
+			return
+		}
+		if c != 'c' {
+			break
+		}
+		r.readLine()
 	}
-	if len(r.htext) == 0 || r.htext[0] != 'p' {
-		r.err = fmt.Errorf("missing problem header: %q", r.htext)
+	line, col := r.line, r.col
+	r.hline, r.hcol = line, col
+	text := r.readLine()
+	if c != 'p' {
+		r.errorf(ErrHeader, line, col, string(text), "missing problem header")
 		return
 	}
-	fields := strings.Fields(string(r.htext))
-	nextfield := func() (f string) {
-		if len(fields) == 0 {
-			return ""
+	fs := fields(text, col)
+	nextfield := func() (f field) {
+		if len(fs) == 0 {
+			return field{"", col + len(text)}
 		}
-		f, fields = fields[0], fields[1:]
+		f, fs = fs[0], fs[1:]
 		return f
 	}
-	if nextfield() != "p" {
-		r.err = fmt.Errorf("missing problem header")
+	if f := nextfield(); f.text != "p" {
+		r.errorf(ErrHeader, line, f.col, f.text, "missing problem header")
 		return
 	}
-	switch format := nextfield(); format {
+	h := &Header{}
+	switch format := nextfield(); format.text {
 	case "cnf":
+	case "inccnf":
+		h.Incremental = true
 	case "":
-		r.err = fmt.Errorf("missing instance format in header")
+		r.errorf(ErrHeader, line, format.col, "", "missing instance format in header")
 		return
 	default:
-		r.err = fmt.Errorf("invalid instance format in header: %q", format)
+		r.errorf(ErrHeader, line, format.col, format.text, "invalid instance format in header")
 		return
 	}
 
-	h := &Header{}
-	switch _numvar := nextfield(); _numvar {
-	case "":
-		r.err = fmt.Errorf("missing instance nbvar")
-		return
-	default:
-		h.NumVar, r.err = strconv.Atoi(_numvar)
-		if r.err != nil {
+	counts := []struct {
+		name string
+		n    *int
+	}{
+		{"nbvar", &h.NumVar},
+		{"nbclause", &h.NumClause},
+	}
+	if h.Incremental {
+		counts = nil
+	}
+	for _, x := range counts {
+		f := nextfield()
+		if f.text == "" {
+			r.errorf(ErrHeader, line, f.col, "", "missing instance %s", x.name)
 			return
 		}
-	}
-
-	switch _numclause := nextfield(); _numclause {
-	case "":
-		r.err = fmt.Errorf("missing instance nbclause")
-		return
-	default:
-		h.NumClause, r.err = strconv.Atoi(_numclause)
-		if r.err != nil {
+		n, err := strconv.Atoi(f.text)
+		if err != nil || n < 0 {
+			r.errorf(ErrHeader, line, f.col, f.text, "invalid instance %s", x.name)
 			return
 		}
+		*x.n = n
 	}
 
-	if len(fields) > 0 {
-		r.err = fmt.Errorf("too many fields in header")
+	if len(fs) > 0 {
+		r.errorf(ErrHeader, line, fs[0].col, fs[0].text, "too many fields in header")
 		return
 	}
 
 	r.h = h
-	r.c = make([]Lit, r.h.NumVar)
+	r.c = make([]Lit, 0, r.h.NumVar)
 }
 
 // Clause returns the last clause decoded from the input stream.  The
@@ -143,76 +286,230 @@ func (r *Decoder) Clause() []Lit {
 	return r.c
 }
 
-// Decode decodes a clause from the input stream.  If r can decode a clause
-// true is returned and the clause can be inspected or copied using r.Clause().
-// If no clause can be decoded false is returned and r.Err() will return any
-// encountered error.  If the stream was fully consumed than false will be
-// returned and r.Err() will return nil.
-func (r *Decoder) Decode() bool {
-	r.readHeader()
+// Assumption returns true if the last line decoded was an iCNF assumption
+// line, in which case Clause returns the assumptions.
+func (r *Decoder) Assumption() bool {
+	return r.assump && r.err == nil
+}
+
+// Prefix returns the QDIMACS quantifier blocks decoded from the input stream.
+// Quantifier lines must precede all clauses.  Adjacent blocks with the same
+// quantifier are merged.
+func (r *Decoder) Prefix() []QuantBlock {
 	if r.err != nil {
+		return nil
+	}
+	return r.q
+}
+
+// readQuant parses a quantifier line, which begins at line and col.
+func (r *Decoder) readQuant(text []byte, line, col int) bool {
+	if r.n > 0 {
+		r.errorf(ErrQuantifier, line, col, string(text[:1]), "invalid quantifier line: quantifier after clauses")
 		return false
 	}
+	if r.qseen == nil {
+		r.qseen = make([]bool, r.h.NumVar+1)
+	}
 
-	if !r.skipComments() {
-		r.err = r.s.Err()
+	q := Quantifier(text[0])
+	fs := fields(text[1:], col+1)
+	if len(fs) == 0 || fs[len(fs)-1].text != "0" {
+		r.errorf(ErrTermination, line, col+len(text), "", "invalid quantifier line: missing terminating null")
 		return false
 	}
+	var vars []int
+	for _, f := range fs[:len(fs)-1] {
+		v, err := strconv.Atoi(f.text)
+		if err != nil {
+			r.errorf(ErrLiteral, line, f.col, f.text, "invalid quantifier line: failed to parse variable")
+			return false
+		}
+		if v <= 0 || v > r.h.NumVar {
+			r.errorf(ErrRange, line, f.col, f.text, "invalid quantifier line: variable outside of range")
+			return false
+		}
+		if r.qseen[v] {
+			r.errorf(ErrDuplicate, line, f.col, f.text, "invalid quantifier line: variable quantified twice")
+			return false
+		}
+		r.qseen[v] = true
+		vars = append(vars, v)
+	}
+
+	if len(r.q) > 0 && r.q[len(r.q)-1].Quant == q {
+		r.q[len(r.q)-1].Vars = append(r.q[len(r.q)-1].Vars, vars...)
+	} else {
+		r.q = append(r.q, QuantBlock{Quant: q, Vars: vars})
+	}
+	return true
+}
+
+// readLit consumes an integer token.  The text of the token is left in
+// r.buf.
+func (r *Decoder) readLit() (x int, ok bool) {
+	r.tokLine, r.tokCol = r.line, r.col
+	r.buf = r.buf[:0]
+	neg := false
+	ndigit := 0
+	valid := true
+	for first := true; ; first = false {
+		c, ok := r.peekByte()
+		if !ok || isSpace(c) {
+			break
+		}
+		r.skipByte()
+		r.buf = append(r.buf, c)
+		switch {
+		case first && c == '-':
+			neg = true
+		case '0' <= c && c <= '9':
+			ndigit++
+			x = 10*x + int(c-'0')
+			if x > math.MaxInt32 {
+				valid = false
+			}
+		default:
+			valid = false
+		}
+	}
+	if r.err != nil {
+		return 0, false
+	}
+	if !valid || ndigit == 0 {
+		r.errorf(ErrLiteral, r.tokLine, r.tokCol, string(r.buf), "invalid clause line: failed to parse literal")
+		return 0, false
+	}
+	if neg {
+		x = -x
+	}
+	return x, true
+}
 
-	if r.n >= r.h.NumClause {
-		r.err = fmt.Errorf("too many clauses")
+// Decode decodes a clause from the input stream.  If r can decode a clause
+// true is returned and the clause can be inspected or copied using r.Clause().
+// If no clause can be decoded false is returned and r.Err() will return any
+// encountered error.  If the stream was fully consumed than false will be
+// returned and r.Err() will return nil.
+func (r *Decoder) Decode() bool {
+	r.readHeader()
+	if r.err != nil || r.done {
 		return false
 	}
-	r.n++
 
 	r.c = r.c[:0]
-
-	lineFull := r.s.Bytes()
-	line := lineFull
-	foundTerm := false
+	r.assump = false
+	var line, col int // start of the clause
 	for {
-		iNotSpace := bytes.IndexFunc(line, func(c rune) bool { return !unicode.IsSpace(c) })
-		if iNotSpace < 0 {
-			if !foundTerm && len(r.c) > 0 {
-				r.err = fmt.Errorf("invalid clause line: missing terminating null")
+		newline := r.skipSpace()
+		if newline && (len(r.c) > 0 || r.assump) && !r.Lenient {
+			r.errorf(ErrTermination, line, col, "", "invalid clause line: missing terminating null")
+			return false
+		}
+		c, ok := r.peekByte()
+		if !ok {
+			if r.err != nil {
+				return false
+			}
+			r.done = true
+			if len(r.c) == 0 && !r.assump {
 				return false
 			}
+			if !r.Lenient {
+				r.errorf(ErrTermination, line, col, "", "invalid clause line: missing terminating null")
+				return false
+			}
+			if !r.assump {
+				r.n++
+			}
 			return true
 		}
-		if foundTerm {
-			r.err = fmt.Errorf("invalid clause line: unexpected null literal")
-			return false
-		}
 
-		line = line[iNotSpace:]
-		iSpace := bytes.IndexFunc(line, unicode.IsSpace)
-		var field []byte
-		if iSpace < 0 {
-			field = line
-			line = nil
-		} else {
-			field = line[:iSpace]
-			line = line[iSpace:]
+		startLine := r.line != r.tokLine
+		switch {
+		case c == 'c' && (startLine || r.Lenient):
+			r.readLine()
+			continue
+		case c == '%' && startLine && r.Lenient:
+			r.done = true
+			if len(r.c) == 0 && !r.assump {
+				return false
+			}
+			if !r.assump {
+				r.n++
+			}
+			return true
+		case c == 'a' && startLine && len(r.c) == 0 && !r.assump && r.h.Incremental:
+			line, col = r.line, r.col
+			r.cline, r.ccol = line, col
+			r.tokLine, r.tokCol = line, col
+			r.skipByte()
+			if c, ok := r.peekByte(); ok && !isSpace(c) {
+				r.errorf(ErrLiteral, line, col, "a"+string(c), "invalid assumption line")
+				return false
+			}
+			r.assump = true
+			continue
+		case (c == 'a' || c == 'e') && startLine && len(r.c) == 0 && !r.h.Incremental:
+			qline, qcol := r.line, r.col
+			if r.qline == 0 {
+				r.qline, r.qcol = qline, qcol
+			}
+			if !r.readQuant(r.readLine(), qline, qcol) {
+				return false
+			}
+			continue
 		}
 
-		x, err := strconv.Atoi(*(*string)(unsafe.Pointer(&field)))
-		if err != nil {
-			r.err = fmt.Errorf("invalid clause line: failed to parse literal: %v", err)
+		if len(r.c) == 0 && !r.assump {
+			line, col = r.line, r.col
+			r.cline, r.ccol = line, col
+			if !r.Lenient && !r.h.Incremental && r.n >= r.h.NumClause {
+				r.errorf(ErrClauseCount, line, col, "", "too many clauses")
+				return false
+			}
+		}
+		x, ok := r.readLit()
+		if !ok {
 			return false
 		}
 		if x == 0 {
-			foundTerm = true
-			continue
+			if !r.Lenient && !r.endLine() {
+				return false
+			}
+			if !r.assump {
+				r.n++
+			}
+			return true
 		}
 
 		lit := Lit(x)
-		v := lit.Var()
-
-		if v > r.h.NumVar {
-			r.err = fmt.Errorf("invalid clause line: variable outside of range")
+		if lit.Var() > r.h.NumVar && !r.h.Incremental {
+			r.errorf(ErrRange, r.tokLine, r.tokCol, string(r.buf), "invalid clause line: variable outside of range")
 			return false
 		}
-
 		r.c = append(r.c, lit)
 	}
 }
+
+// endLine checks that no tokens follow a terminating null on its line.
+func (r *Decoder) endLine() bool {
+	for {
+		c, ok := r.peekByte()
+		if !ok || c == '\n' {
+			return r.err == nil
+		}
+		if !isSpace(c) {
+			line, col := r.line, r.col
+			var tok []byte
+			for ok && !isSpace(c) {
+				tok = append(tok, c)
+				r.skipByte()
+				c, ok = r.peekByte()
+			}
+			r.errorf(ErrTermination, line, col, string(tok), "invalid clause line: unexpected null literal")
+			return false
+		}
+		r.skipByte()
+	}
+}
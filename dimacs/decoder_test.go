@@ -6,9 +6,14 @@
 package dimacs
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestDecoder(t *testing.T) {
@@ -51,3 +56,115 @@ func TestDecoder(t *testing.T) {
 		}
 	}
 }
+
+func TestDecoder_error(t *testing.T) {
+	tests := []struct {
+		in   string
+		kind ErrorKind
+		line int
+		col  int
+		tok  string
+	}{
+		{"", ErrHeader, 1, 1, ""},
+		{"c comment\nq cnf 1 1\n", ErrHeader, 2, 1, "q cnf 1 1"},
+		{"p dnf 3 1\n", ErrHeader, 1, 3, "dnf"},
+		{"p cnf 3\n", ErrHeader, 1, 8, ""},
+		{"p cnf x 1\n", ErrHeader, 1, 7, "x"},
+		{"p cnf 3 1 1\n", ErrHeader, 1, 11, "1"},
+		{"p cnf 3 1\n1 2\n0\n", ErrTermination, 2, 1, ""},
+		{"p cnf 3 1\n1 2", ErrTermination, 2, 1, ""},
+		{"p cnf 3 2\n1 0 2 0\n", ErrTermination, 2, 5, "2"},
+		{"p cnf 3 1\n1 0\n2 0\n", ErrClauseCount, 3, 1, ""},
+		{"p cnf 3 1\n1 -4 0\n", ErrRange, 2, 3, "-4"},
+		{"p cnf 3 1\n1 x 0\n", ErrLiteral, 2, 3, "x"},
+		{"p cnf 3 1\n1 - 0\n", ErrLiteral, 2, 3, "-"},
+		{"p cnf 3 1\n1 99999999999999999999 0\n", ErrLiteral, 2, 3, "99999999999999999999"},
+		{"p cnf 3 1\ne 1 0\n e 1 2 0\n", ErrDuplicate, 3, 4, "1"},
+		{"p cnf 3 1\ne 1 0\n1 0\na 2 0\n", ErrQuantifier, 4, 1, "a"},
+	}
+	for i, test := range tests {
+		_, err := DecodeQProblem(strings.NewReader(test.in))
+		var derr *Error
+		if !errors.As(err, &derr) {
+			t.Errorf("test %d: %v", i, err)
+			continue
+		}
+		if derr.Kind != test.kind || derr.Line != test.line || derr.Column != test.col || derr.Token != test.tok {
+			t.Errorf("test %d: %s error %d:%d %q (expected %s %d:%d %q)", i, derr.Kind, derr.Line, derr.Column, derr.Token, test.kind, test.line, test.col, test.tok)
+		}
+	}
+
+	_, err := DecodeProblem(strings.NewReader("p cnf 2 1\nc\na 1 0\n1 2 0\n"))
+	var derr *Error
+	if !errors.As(err, &derr) || derr.Kind != ErrQuantifier || derr.Line != 3 {
+		t.Errorf("quantified: %v", err)
+	}
+
+	// errors reading the input are not decoding errors
+	_, err = DecodeProblem(io.MultiReader(strings.NewReader("p cnf 2 1\n1 "), iotest.TimeoutReader(strings.NewReader("2 0\n"))))
+	if err != iotest.ErrTimeout {
+		t.Errorf("read error: %v", err)
+	}
+}
+
+func TestDecoder_lenient(t *testing.T) {
+	tests := []struct {
+		in string
+		p  *Problem
+	}{
+		{
+			"p cnf 3 2\n1\n-2 0 c wrapped\n3\n0\n",
+			&Problem{3, [][]Lit{{1, -2}, {3}}},
+		},
+		{
+			"p cnf 3 1\r\n1 -2 0 2 3 0\r\n-3 0\r\n",
+			&Problem{3, [][]Lit{{1, -2}, {2, 3}, {-3}}},
+		},
+		{
+			"p cnf 3 5\n1 2 0\n\n  -3",
+			&Problem{3, [][]Lit{{1, 2}, {-3}}},
+		},
+		{
+			"c satlib\np cnf 3 2\n 1 2 0\n-3 0\n%\n0\n\n",
+			&Problem{3, [][]Lit{{1, 2}, {-3}}},
+		},
+	}
+	for i, test := range tests {
+		d := NewDecoder(strings.NewReader(test.in))
+		d.Lenient = true
+		p := &Problem{NumVar: d.Header().NumVar, Clauses: [][]Lit{}}
+		for d.Decode() {
+			p.newClause(d.Clause())
+		}
+		if d.Err() != nil {
+			t.Errorf("test %d: %v", i, d.Err())
+			continue
+		}
+		if !reflect.DeepEqual(p, test.p) {
+			t.Errorf("test %d: p %v (!= %v)", i, p, test.p)
+		}
+
+		_, err := DecodeProblem(strings.NewReader(test.in))
+		if err == nil {
+			t.Errorf("test %d: strict decoding succeeded", i)
+		}
+	}
+}
+
+func TestDecoder_longLine(t *testing.T) {
+	// the clause is longer than the default limit of a bufio.Scanner
+	const n = 20000
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "p cnf %d 1\n", n)
+	for v := 1; v <= n; v++ {
+		fmt.Fprintf(&buf, "%d ", -v)
+	}
+	buf.WriteString("0\n")
+	p, err := DecodeProblem(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Clauses) != 1 || len(p.Clauses[0]) != n || p.Clauses[0][n-1] != -n {
+		t.Errorf("decoded %d clauses", len(p.Clauses))
+	}
+}
@@ -7,15 +7,14 @@
 // satisfiability problem statement.
 package dimacs
 
-import (
-	"io"
-	"os"
-)
+import "io"
 
-// Header precedes clause data in a DIMACS data stream
+// Header precedes clause data in a DIMACS data stream.  The header of an
+// incremental (iCNF) stream has no counts and its variables are unbounded.
 type Header struct {
-	NumVar    int
-	NumClause int
+	NumVar      int
+	NumClause   int
+	Incremental bool
 }
 
 // Problem is the statement of a SAT problem in CNF.
@@ -24,9 +23,10 @@ type Problem struct {
 	Clauses [][]Lit
 }
 
-// DecodeFile opens path and decodes its contents using DecodeProblem.
+// DecodeFile opens path with Open and decodes its contents using
+// DecodeProblem.
 func DecodeFile(path string) (*Problem, error) {
-	f, err := os.Open(path)
+	f, err := Open(path)
 	if err != nil {
 		return nil, err
 	}
@@ -34,34 +34,81 @@ func DecodeFile(path string) (*Problem, error) {
 	return DecodeProblem(f)
 }
 
-// DecodeProblem decodes the contents of r into a new Problem.
+// DecodeProblem decodes the contents of r into a new Problem.  Input with a
+// QDIMACS quantifier prefix must be decoded with DecodeQProblem.
 func DecodeProblem(r io.Reader) (*Problem, error) {
-	d := NewDecoder(r)
-	h := d.Header()
-	if d.Err() != nil {
-		return nil, d.Err()
+	return NewDecoder(r).Problem()
+}
+
+// Problem decodes the remaining input of r into a new Problem, as
+// DecodeProblem does, so that the problem may be decoded leniently.
+func (r *Decoder) Problem() (*Problem, error) {
+	h := r.Header()
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+	err := r.CheckCNF()
+	if err != nil {
+		return nil, err
 	}
 	p := &Problem{}
 	p.NumVar = h.NumVar
-	p.Clauses = make([][]Lit, 0, h.NumClause)
-	for d.Decode() {
-		p.newClause(d.Clause())
+	p.Clauses = [][]Lit{}
+	if !r.Lenient {
+		// a lenient header may not be a reliable estimate
+		p.Clauses = make([][]Lit, 0, h.NumClause)
 	}
-	if d.Err() != nil {
-		return nil, d.Err()
+	for r.Decode() {
+		p.newClause(r.Clause())
+	}
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+	err = r.CheckCNF()
+	if err != nil {
+		return nil, err
 	}
 	return p, nil
 }
 
+// CheckCNF returns an *Error if the input decoded by r is not a plain CNF
+// problem because it has an iCNF header or a QDIMACS quantifier prefix.
+// Quantifier lines precede all clauses, so a caller adding clauses to a
+// solver as they are decoded may call CheckCNF after the first clause.
+func (r *Decoder) CheckCNF() error {
+	if r.h != nil && r.h.Incremental {
+		return &Error{
+			Kind:   ErrHeader,
+			Line:   r.hline,
+			Column: r.hcol,
+			Msg:    "incremental problem",
+		}
+	}
+	if len(r.Prefix()) > 0 {
+		return &Error{
+			Kind:   ErrQuantifier,
+			Line:   r.qline,
+			Column: r.qcol,
+			Msg:    "quantified problem",
+		}
+	}
+	return nil
+}
+
 // EncodeFile encodes p in DIMACS format and writes the resulting bytes to a
-// new file at path.
+// new file at path.  The file is compressed according to its extension as
+// described by Create.
 func EncodeFile(path string, p *Problem) error {
-	f, err := os.Create(path)
+	f, err := Create(path)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	return EncodeProblem(f, p)
+	err = EncodeProblem(f, p)
+	errc := f.Close()
+	if err != nil {
+		return err
+	}
+	return errc
 }
 
 // EncodeProblem encodes p in DIMACS format and writes the resulting bytes to
@@ -18,6 +18,7 @@ type Encoder struct {
 	h    *Header
 	seen []bool
 	n    int
+	line int // lines written
 }
 
 // NewEncoder initializes a new Encoder.  The returned encoder must be closed
@@ -29,39 +30,146 @@ func NewEncoder(w io.Writer) *Encoder {
 }
 
 // WriteHeader encodes and writes h to the output stream.  WriteHeader must be
-// called only once, before any clauses have been written.
+// called only once, before any clauses have been written.  The counts of an
+// incremental header are not written or enforced.
 func (enc *Encoder) WriteHeader(h *Header) error {
 	enc.h = h
 	enc.seen = make([]bool, h.NumVar+1)
+	enc.line++
+	if h.Incremental {
+		_, err := fmt.Fprintf(enc.w, "p inccnf\n")
+		return err
+	}
 	_, err := fmt.Fprintf(enc.w, "p cnf %d %d\n", h.NumVar, h.NumClause)
 	return err
 }
 
+// errorf returns an *Error at column col of the next line of output.
+func (enc *Encoder) errorf(kind ErrorKind, col int, tok string, format string, v ...interface{}) error {
+	return &Error{
+		Kind:   kind,
+		Line:   enc.line + 1,
+		Column: col,
+		Token:  tok,
+		Msg:    fmt.Sprintf(format, v...),
+	}
+}
+
+// WritePrefix encodes and writes the QDIMACS quantifier blocks of prefix to
+// the output stream.  WritePrefix must be called after WriteHeader, before
+// any clauses have been written.
+func (enc *Encoder) WritePrefix(prefix []QuantBlock) error {
+	if enc.h == nil {
+		return enc.errorf(ErrOrder, 1, "", "no header")
+	}
+	if enc.n > 0 {
+		return enc.errorf(ErrQuantifier, 1, string(prefix[0].Quant), "prefix after clauses")
+	}
+	for _, b := range prefix {
+		if b.Quant != Exists && b.Quant != Forall {
+			return enc.errorf(ErrQuantifier, 1, string(b.Quant), "invalid quantifier")
+		}
+		col := 3
+		for _, v := range b.Vars {
+			if v <= 0 || v > enc.h.NumVar {
+				return enc.errorf(ErrRange, col, strconv.Itoa(v), "invalid variable")
+			}
+			col += len(strconv.Itoa(v)) + 1
+		}
+		err := enc.writeString(string(b.Quant))
+		if err != nil {
+			return err
+		}
+		for _, v := range b.Vars {
+			err = enc.writeString(" " + strconv.Itoa(v))
+			if err != nil {
+				return err
+			}
+		}
+		err = enc.writeString(" 0\n")
+		if err != nil {
+			return err
+		}
+		enc.line++
+	}
+	return nil
+}
+
 // Encode encodes clause and writes it to the output stream.
 func (enc *Encoder) Encode(clause []Lit) error {
 	if enc.h == nil {
-		return fmt.Errorf("no header")
+		return enc.errorf(ErrOrder, 1, "", "no header")
+	}
+	if !enc.h.Incremental {
+		if len(clause) > enc.h.NumVar {
+			return enc.errorf(ErrDuplicate, 1, "", "too many literals")
+		}
+		if enc.n >= enc.h.NumClause {
+			return enc.errorf(ErrClauseCount, 1, "", "too many clauses supplied")
+		}
+	}
+	err := enc.checkLits(clause, 1, true)
+	if err != nil {
+		return err
+	}
+	err = enc.writeLits("", clause)
+	if err != nil {
+		return err
+	}
+	enc.n++
+	return nil
+}
+
+// EncodeAssumptions writes an iCNF assumption line requesting a solve under
+// the literals of assumptions.  The header must be incremental.
+func (enc *Encoder) EncodeAssumptions(assumptions []Lit) error {
+	if enc.h == nil {
+		return enc.errorf(ErrOrder, 1, "", "no header")
 	}
-	if len(clause) > enc.h.NumVar {
-		return fmt.Errorf("too many literals")
+	if !enc.h.Incremental {
+		return enc.errorf(ErrHeader, 1, "a", "assumptions in a non-incremental problem")
 	}
-	if enc.n >= enc.h.NumClause {
-		return fmt.Errorf("too many clauses supplied")
+	err := enc.checkLits(assumptions, 3, false)
+	if err != nil {
+		return err
 	}
+	return enc.writeLits("a ", assumptions)
+}
+
+// checkLits validates the literals of a line which begin at column col.
+// Duplicate variables are an error if unique is true.
+func (enc *Encoder) checkLits(lits []Lit, col int, unique bool) error {
 	for i := range enc.seen {
 		enc.seen[i] = false
 	}
-	for _, lit := range clause {
+	for _, lit := range lits {
+		s := strconv.Itoa(int(lit))
 		v := lit.Var()
-		if v == 0 || v > enc.h.NumVar {
-			return fmt.Errorf("invalid literal: %d", lit)
+		if v == 0 {
+			return enc.errorf(ErrLiteral, col, s, "invalid literal")
 		}
-		if enc.seen[v] {
-			return fmt.Errorf("duplicate variable: %d", v)
+		if v > enc.h.NumVar && !enc.h.Incremental {
+			return enc.errorf(ErrRange, col, s, "invalid literal")
+		}
+		for v >= len(enc.seen) {
+			enc.seen = append(enc.seen, false)
+		}
+		if unique && enc.seen[v] {
+			return enc.errorf(ErrDuplicate, col, s, "duplicate variable")
 		}
 		enc.seen[v] = true
+		col += len(s) + 1
+	}
+	return nil
+}
+
+// writeLits writes a line containing prefix and the null terminated lits.
+func (enc *Encoder) writeLits(prefix string, lits []Lit) error {
+	err := enc.writeString(prefix)
+	if err != nil {
+		return err
 	}
-	for _, lit := range clause {
+	for _, lit := range lits {
 		s := strconv.Itoa(int(lit))
 		err := enc.writeString(s)
 		if err != nil {
@@ -72,11 +180,11 @@ func (enc *Encoder) Encode(clause []Lit) error {
 			return err
 		}
 	}
-	err := enc.writeString("0\n")
+	err = enc.writeString("0\n")
 	if err != nil {
 		return err
 	}
-	enc.n++
+	enc.line++
 	return nil
 }
 
@@ -96,10 +204,10 @@ func (enc *Encoder) writeString(s string) error {
 // is returned.
 func (enc *Encoder) Close() error {
 	if enc.h == nil {
-		return fmt.Errorf("no output written")
+		return enc.errorf(ErrOrder, 1, "", "no output written")
 	}
-	if enc.n != enc.h.NumClause {
-		return fmt.Errorf("not enough clauses encoded")
+	if enc.n != enc.h.NumClause && !enc.h.Incremental {
+		return enc.errorf(ErrClauseCount, 1, "", "not enough clauses encoded")
 	}
 	return enc.w.Flush()
 }
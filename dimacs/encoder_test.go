@@ -7,6 +7,8 @@ package dimacs
 
 import (
 	"bytes"
+	"errors"
+	"io/ioutil"
 	"testing"
 )
 
@@ -46,3 +48,40 @@ func TestEncoder(t *testing.T) {
 		}
 	}
 }
+
+func TestEncoder_error(t *testing.T) {
+	tests := []struct {
+		p    *QProblem
+		kind ErrorKind
+		line int
+		col  int
+		tok  string
+	}{
+		{&QProblem{Problem{3, [][]Lit{{1, -2}, {2, 0}}}, nil}, ErrLiteral, 3, 3, "0"},
+		{&QProblem{Problem{3, [][]Lit{{1, -2, 4}}}, nil}, ErrRange, 2, 6, "4"},
+		{&QProblem{Problem{3, [][]Lit{{-3, 1, 3}}}, nil}, ErrDuplicate, 2, 6, "3"},
+		{&QProblem{Problem{3, nil}, []QuantBlock{{Exists, []int{1}}, {Forall, []int{2, 5}}}}, ErrRange, 3, 5, "5"},
+		{&QProblem{Problem{3, nil}, []QuantBlock{{'x', []int{1}}}}, ErrQuantifier, 2, 1, "x"},
+	}
+	for i, test := range tests {
+		err := EncodeQProblem(ioutil.Discard, test.p)
+		var derr *Error
+		if !errors.As(err, &derr) {
+			t.Errorf("test %d: %v", i, err)
+			continue
+		}
+		if derr.Kind != test.kind || derr.Line != test.line || derr.Column != test.col || derr.Token != test.tok {
+			t.Errorf("test %d: %s error %d:%d %q (expected %s %d:%d %q)", i, derr.Kind, derr.Line, derr.Column, derr.Token, test.kind, test.line, test.col, test.tok)
+		}
+	}
+
+	enc := NewEncoder(ioutil.Discard)
+	err := enc.WriteHeader(&Header{NumVar: 1, NumClause: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var derr *Error
+	if err := enc.Close(); !errors.As(err, &derr) || derr.Kind != ErrClauseCount {
+		t.Errorf("close: %v", err)
+	}
+}
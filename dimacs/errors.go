@@ -0,0 +1,69 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import "fmt"
+
+// ErrorKind classifies an Error.
+type ErrorKind int
+
+// Available ErrorKind values.
+const (
+	ErrHeader      ErrorKind = iota + 1 // the problem header is missing or malformed
+	ErrLiteral                          // a literal could not be parsed
+	ErrRange                            // a variable is outside the range given in the header
+	ErrDuplicate                        // a variable appears twice in a clause or prefix
+	ErrTermination                      // a clause or quantifier line is not terminated correctly
+	ErrClauseCount                      // the number of clauses does not match the header
+	ErrQuantifier                       // a quantifier line is malformed or misplaced
+	ErrOrder                            // data was written before the header or out of order
+	ErrStatus                           // a solution status line is missing or malformed
+	ErrWeight                           // the weight of a soft clause is missing or malformed
+)
+
+var errorKindStrings = []string{
+	ErrHeader:      "header",
+	ErrLiteral:     "literal",
+	ErrRange:       "range",
+	ErrDuplicate:   "duplicate",
+	ErrTermination: "termination",
+	ErrClauseCount: "clause count",
+	ErrQuantifier:  "quantifier",
+	ErrOrder:       "order",
+	ErrStatus:      "status",
+	ErrWeight:      "weight",
+}
+
+func (k ErrorKind) String() string {
+	if k > 0 && int(k) < len(errorKindStrings) {
+		return errorKindStrings[k]
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// Error is an error in DIMACS data.  A Decoder returns an Error for malformed
+// input and an Encoder returns an Error for data which cannot be encoded.
+// Errors reading or writing the underlying stream are returned unchanged, so
+// Error distinguishes bad data from failed I/O.
+//
+//		var derr *dimacs.Error
+//		if errors.As(err, &derr) {
+//			log.Printf("%s at line %d", derr.Kind, derr.Line)
+//		}
+type Error struct {
+	Kind   ErrorKind
+	Line   int    // line of the error, starting from 1
+	Column int    // column of the error, starting from 1
+	Token  string // the offending token, if any
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("%d:%d: %s: %q", e.Line, e.Column, e.Msg, e.Token)
+}
@@ -0,0 +1,110 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import "io"
+
+// IncProblem is an incremental SAT problem in iCNF format.  Clauses are added
+// and the problem is solved under assumptions in the order of Steps.
+//
+//		p inccnf
+//		1 -2 0
+//		a 2 0
+//		-1 0
+//		a 0
+type IncProblem struct {
+	NumVar int // the largest variable in Steps
+	Steps  []IncStep
+}
+
+// IncStep is a line of an IncProblem.  A step adds the clause Lits to the
+// problem or, if Solve is true, solves the problem assuming Lits.
+type IncStep struct {
+	Solve bool
+	Lits  []Lit
+}
+
+// DecodeIncFile opens path with Open and decodes its contents using
+// DecodeIncProblem.
+func DecodeIncFile(path string) (*IncProblem, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeIncProblem(f)
+}
+
+// DecodeIncProblem decodes the iCNF contents of r into a new IncProblem.  The
+// input must have an incremental header.
+func DecodeIncProblem(r io.Reader) (*IncProblem, error) {
+	d := NewDecoder(r)
+	h := d.Header()
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	if !h.Incremental {
+		return nil, &Error{
+			Kind:   ErrHeader,
+			Line:   d.hline,
+			Column: d.hcol,
+			Msg:    "not an incremental problem",
+		}
+	}
+	p := &IncProblem{}
+	for d.Decode() {
+		step := IncStep{Solve: d.Assumption()}
+		step.Lits = make([]Lit, len(d.Clause()))
+		copy(step.Lits, d.Clause())
+		for _, x := range step.Lits {
+			if x.Var() > p.NumVar {
+				p.NumVar = x.Var()
+			}
+		}
+		p.Steps = append(p.Steps, step)
+	}
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	return p, nil
+}
+
+// EncodeIncFile encodes p in iCNF format and writes the resulting bytes to a
+// new file at path.  The file is compressed according to its extension as
+// described by Create.
+func EncodeIncFile(path string, p *IncProblem) error {
+	f, err := Create(path)
+	if err != nil {
+		return err
+	}
+	err = EncodeIncProblem(f, p)
+	errc := f.Close()
+	if err != nil {
+		return err
+	}
+	return errc
+}
+
+// EncodeIncProblem encodes p in iCNF format and writes the resulting bytes to
+// w.
+func EncodeIncProblem(w io.Writer, p *IncProblem) error {
+	enc := NewEncoder(w)
+	err := enc.WriteHeader(&Header{Incremental: true})
+	if err != nil {
+		return err
+	}
+	for _, step := range p.Steps {
+		if step.Solve {
+			err = enc.EncodeAssumptions(step.Lits)
+		} else {
+			err = enc.Encode(step.Lits)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
@@ -0,0 +1,130 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeIncProblem(t *testing.T) {
+	p := &IncProblem{
+		NumVar: 3,
+		Steps: []IncStep{
+			{false, []Lit{1, -2}},
+			{true, []Lit{2}},
+			{false, []Lit{-1, 3}},
+			{true, []Lit{}},
+			{true, []Lit{-3, 2, -3}},
+		},
+	}
+	var buf bytes.Buffer
+	err := EncodeIncProblem(&buf, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := "p inccnf\n1 -2 0\na 2 0\n-1 3 0\na 0\na -3 2 -3 0\n"
+	if buf.String() != out {
+		t.Errorf("output %q (!= %q)", buf.String(), out)
+	}
+
+	p2, err := DecodeIncProblem(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p2, p) {
+		t.Errorf("decoded %v (!= %v)", p2, p)
+	}
+}
+
+func TestDecodeIncProblem(t *testing.T) {
+	in := "c trace\np inccnf\n1 2 0\nc a comment\na -1 0\n\n-2 1000 0\n"
+	p, err := DecodeIncProblem(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &IncProblem{
+		NumVar: 1000,
+		Steps: []IncStep{
+			{false, []Lit{1, 2}},
+			{true, []Lit{-1}},
+			{false, []Lit{-2, 1000}},
+		},
+	}
+	if !reflect.DeepEqual(p, expect) {
+		t.Errorf("decoded %v (!= %v)", p, expect)
+	}
+}
+
+func TestDecodeIncProblem_error(t *testing.T) {
+	tests := []struct {
+		in   string
+		kind ErrorKind
+		line int
+		col  int
+	}{
+		{"p cnf 2 1\n1 2 0\n", ErrHeader, 1, 1},
+		{"p inccnf 2 1\n", ErrHeader, 1, 10},
+		{"p inccnf\na 1\n", ErrTermination, 2, 1},
+		{"p inccnf\na1 0\n", ErrLiteral, 2, 1},
+		{"p inccnf\n1 0\na 1 0 2\n", ErrTermination, 3, 7},
+		{"p inccnf\ne 1 0\n", ErrLiteral, 2, 1},
+	}
+	for i, test := range tests {
+		_, err := DecodeIncProblem(strings.NewReader(test.in))
+		var derr *Error
+		if !errors.As(err, &derr) {
+			t.Errorf("test %d: %v", i, err)
+			continue
+		}
+		if derr.Kind != test.kind || derr.Line != test.line || derr.Column != test.col {
+			t.Errorf("test %d: %v %v (expected %v at %d:%d)", i, derr.Kind, err, test.kind, test.line, test.col)
+		}
+	}
+
+	// incremental input is not a plain problem
+	for _, in := range []string{"p inccnf\n1 0\na 0\n", "p inccnf\n"} {
+		_, err := DecodeProblem(strings.NewReader(in))
+		if err == nil {
+			t.Errorf("%q: no error", in)
+		}
+		_, err = DecodeQProblem(strings.NewReader(in))
+		if err == nil {
+			t.Errorf("%q: no error", in)
+		}
+	}
+}
+
+func TestEncoder_assumptions(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	err := enc.WriteHeader(&Header{NumVar: 2, NumClause: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = enc.EncodeAssumptions([]Lit{1})
+	var derr *Error
+	if !errors.As(err, &derr) || derr.Kind != ErrHeader {
+		t.Errorf("assumptions: %v", err)
+	}
+
+	enc = NewEncoder(&buf)
+	err = enc.WriteHeader(&Header{Incremental: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = enc.EncodeAssumptions([]Lit{1, 0})
+	if !errors.As(err, &derr) || derr.Kind != ErrLiteral || derr.Line != 2 || derr.Column != 5 {
+		t.Errorf("assumptions: %v", err)
+	}
+	err = enc.Encode([]Lit{1, -1})
+	if !errors.As(err, &derr) || derr.Kind != ErrDuplicate {
+		t.Errorf("clause: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import (
+	"io"
+)
+
+// Quantifier is the quantifier of a block of variables in a QDIMACS prefix.
+type Quantifier byte
+
+// Quantifiers are identified by the first character of their QDIMACS lines.
+const (
+	Exists Quantifier = 'e'
+	Forall Quantifier = 'a'
+)
+
+// QuantBlock is a sequence of variables bound by the same quantifier.
+type QuantBlock struct {
+	Quant Quantifier
+	Vars  []int
+}
+
+// QProblem is the statement of a QBF problem in prenex CNF.  Blocks of the
+// prefix are ordered from outermost to innermost.  Variables which do not
+// appear in the prefix are existentially quantified in the outermost block.
+type QProblem struct {
+	Problem
+	Prefix []QuantBlock
+}
+
+// DecodeQFile opens path with Open and decodes its contents using
+// DecodeQProblem.
+func DecodeQFile(path string) (*QProblem, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeQProblem(f)
+}
+
+// DecodeQProblem decodes the QDIMACS contents of r into a new QProblem.  A
+// plain DIMACS problem is decoded with an empty prefix.
+func DecodeQProblem(r io.Reader) (*QProblem, error) {
+	d := NewDecoder(r)
+	h := d.Header()
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	if h.Incremental {
+		return nil, &Error{
+			Kind:   ErrHeader,
+			Line:   d.hline,
+			Column: d.hcol,
+			Msg:    "incremental problem",
+		}
+	}
+	p := &QProblem{}
+	p.NumVar = h.NumVar
+	p.Clauses = make([][]Lit, 0, h.NumClause)
+	for d.Decode() {
+		p.newClause(d.Clause())
+	}
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	p.Prefix = d.Prefix()
+	return p, nil
+}
+
+// EncodeQFile encodes p in QDIMACS format and writes the resulting bytes to a
+// new file at path.  The file is compressed according to its extension as
+// described by Create.
+func EncodeQFile(path string, p *QProblem) error {
+	f, err := Create(path)
+	if err != nil {
+		return err
+	}
+	err = EncodeQProblem(f, p)
+	errc := f.Close()
+	if err != nil {
+		return err
+	}
+	return errc
+}
+
+// EncodeQProblem encodes p in QDIMACS format and writes the resulting bytes
+// to w.
+func EncodeQProblem(w io.Writer, p *QProblem) error {
+	enc := NewEncoder(w)
+	err := enc.WriteHeader(&Header{
+		NumVar:    p.NumVar,
+		NumClause: len(p.Clauses),
+	})
+	if err != nil {
+		return err
+	}
+	err = enc.WritePrefix(p.Prefix)
+	if err != nil {
+		return err
+	}
+	for _, clause := range p.Clauses {
+		err = enc.Encode(clause)
+		if err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
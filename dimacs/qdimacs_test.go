@@ -0,0 +1,104 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeQProblem(t *testing.T) {
+	tests := []struct {
+		in string
+		p  *QProblem
+	}{
+		{
+			"p cnf 3 1\n-1 0\n",
+			&QProblem{Problem{3, [][]Lit{{-1}}}, nil},
+		},
+		{
+			"p cnf 3 1\ne 1 2 0\na 3 0\n-1 3 0\n",
+			&QProblem{
+				Problem{3, [][]Lit{{-1, 3}}},
+				[]QuantBlock{{Exists, []int{1, 2}}, {Forall, []int{3}}},
+			},
+		},
+		{
+			"c quantified\np cnf 4 2\na 1 0\nc merged\na 2 0\ne 3 4 0\n1 2 3 0\n-4 0\n",
+			&QProblem{
+				Problem{4, [][]Lit{{1, 2, 3}, {-4}}},
+				[]QuantBlock{{Forall, []int{1, 2}}, {Exists, []int{3, 4}}},
+			},
+		},
+		{
+			"p cnf 2 0\ne 1 0\n",
+			&QProblem{
+				Problem{2, [][]Lit{}},
+				[]QuantBlock{{Exists, []int{1}}},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		p, err := DecodeQProblem(strings.NewReader(test.in))
+		if err != nil {
+			t.Errorf("test %d: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(p, test.p) {
+			t.Errorf("test %d: p %v (!= %v)", i, p, test.p)
+		}
+	}
+}
+
+func TestDecodeQProblem_error(t *testing.T) {
+	tests := []string{
+		"p cnf 3 1\ne 1 2\n-1 0\n",
+		"p cnf 3 1\ne 1 4 0\n-1 0\n",
+		"p cnf 3 1\ne 1 0\na 1 0\n-1 0\n",
+		"p cnf 3 2\ne 1 0\n-1 0\na 2 0\n2 0\n",
+		"p cnf 3 1\ne x 0\n-1 0\n",
+	}
+	for i, in := range tests {
+		_, err := DecodeQProblem(strings.NewReader(in))
+		if err == nil {
+			t.Errorf("test %d: no error", i)
+		}
+	}
+}
+
+func TestDecodeProblem_quantified(t *testing.T) {
+	_, err := DecodeProblem(strings.NewReader("p cnf 2 1\na 1 0\n1 2 0\n"))
+	if err == nil {
+		t.Errorf("no error")
+	}
+}
+
+func TestEncodeQProblem(t *testing.T) {
+	p := &QProblem{
+		Problem{3, [][]Lit{{-1, 3}, {2}}},
+		[]QuantBlock{{Exists, []int{1, 2}}, {Forall, []int{3}}},
+	}
+	var buf bytes.Buffer
+	err := EncodeQProblem(&buf, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := "p cnf 3 2\ne 1 2 0\na 3 0\n-1 3 0\n2 0\n"
+	if buf.String() != out {
+		t.Errorf("output %q (!= %q)", buf.String(), out)
+	}
+
+	p2, err := DecodeQProblem(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p2, p) {
+		t.Errorf("decoded %v (!= %v)", p2, p)
+	}
+}
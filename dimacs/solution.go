@@ -0,0 +1,191 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Status is the answer stated by a Solution.
+type Status int
+
+// Available Status values.
+const (
+	Unknown Status = iota
+	Satisfiable
+	Unsatisfiable
+)
+
+var statusStrings = []string{
+	Unknown:       "UNKNOWN",
+	Satisfiable:   "SATISFIABLE",
+	Unsatisfiable: "UNSATISFIABLE",
+}
+
+// String returns the text of s used in solution files.
+func (s Status) String() string {
+	if s >= 0 && int(s) < len(statusStrings) {
+		return statusStrings[s]
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// SolutionWidth is the maximum length of the value lines written by
+// EncodeSolution, unless a single literal is longer.
+const SolutionWidth = 78
+
+// Solution is the output of a solver in the format used by the SAT
+// competitions.  The status line is followed by value lines listing the
+// literals true in the model, if the problem is satisfiable.
+//
+//		s SATISFIABLE
+//		v 1 -2 3 0
+type Solution struct {
+	Status Status
+	Model  []Lit
+}
+
+// DecodeSolutionFile opens path with Open and decodes its contents using
+// DecodeSolution.
+func DecodeSolutionFile(path string) (*Solution, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeSolution(f)
+}
+
+// DecodeSolution decodes a solution from r.  Comment lines are ignored.  The
+// value lines of a satisfiable solution must be terminated by a null literal.
+func DecodeSolution(r io.Reader) (*Solution, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<30)
+	sol := &Solution{}
+	var line int
+	var status, term bool
+	for s.Scan() {
+		line++
+		b := s.Bytes()
+		if len(b) == 0 || b[0] == 'c' {
+			continue
+		}
+		fs := fields(b[1:], 2)
+		switch b[0] {
+		case 's':
+			if status {
+				return nil, solutionError(ErrStatus, line, 1, "s", "multiple status lines")
+			}
+			status = true
+			if len(fs) != 1 {
+				return nil, solutionError(ErrStatus, line, 1, string(b), "invalid status line")
+			}
+			switch fs[0].text {
+			case "SATISFIABLE":
+				sol.Status = Satisfiable
+			case "UNSATISFIABLE":
+				sol.Status = Unsatisfiable
+			case "UNKNOWN":
+				sol.Status = Unknown
+			default:
+				return nil, solutionError(ErrStatus, line, fs[0].col, fs[0].text, "invalid status")
+			}
+		case 'v':
+			if !status || sol.Status != Satisfiable {
+				return nil, solutionError(ErrStatus, line, 1, "v", "values without a satisfiable status")
+			}
+			for _, f := range fs {
+				if term {
+					return nil, solutionError(ErrTermination, line, f.col, f.text, "values after terminating null")
+				}
+				x, err := strconv.Atoi(f.text)
+				if err != nil || x < -math.MaxInt32 || x > math.MaxInt32 {
+					return nil, solutionError(ErrLiteral, line, f.col, f.text, "failed to parse literal")
+				}
+				if x == 0 {
+					term = true
+					continue
+				}
+				sol.Model = append(sol.Model, Lit(x))
+			}
+		default:
+			return nil, solutionError(ErrStatus, line, 1, string(b[:1]), "invalid line")
+		}
+	}
+	if s.Err() != nil {
+		return nil, s.Err()
+	}
+	if !status {
+		return nil, solutionError(ErrStatus, line+1, 1, "", "missing status line")
+	}
+	if sol.Status == Satisfiable && !term {
+		return nil, solutionError(ErrTermination, line+1, 1, "", "missing terminating null")
+	}
+	return sol, nil
+}
+
+func solutionError(kind ErrorKind, line, col int, tok string, msg string) *Error {
+	return &Error{Kind: kind, Line: line, Column: col, Token: tok, Msg: msg}
+}
+
+// EncodeSolution writes sol to w.  Value lines are wrapped at SolutionWidth.
+func EncodeSolution(w io.Writer, sol *Solution) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "s %s\n", sol.Status)
+	if sol.Status == Satisfiable {
+		line := []byte("v")
+		for _, x := range append(sol.Model, 0) {
+			tok := strconv.Itoa(int(x))
+			if len(line) > 1 && len(line)+1+len(tok) > SolutionWidth {
+				bw.Write(line)
+				bw.WriteByte('\n')
+				line = append(line[:0], 'v')
+			}
+			line = append(line, ' ')
+			line = append(line, tok...)
+		}
+		bw.Write(line)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// Check returns an error if sol does not state a model of p.  Variables not
+// assigned by the model may take either value, so a clause is satisfied only
+// if the model contains one of its literals.
+func (sol *Solution) Check(p *Problem) error {
+	if sol.Status != Satisfiable {
+		return fmt.Errorf("solution is %s", sol.Status)
+	}
+	value := make([]Lit, p.NumVar+1)
+	for _, x := range sol.Model {
+		v := x.Var()
+		if v > p.NumVar {
+			return fmt.Errorf("model assigns variable %d outside the problem", v)
+		}
+		if value[v] == -x {
+			return fmt.Errorf("model assigns variable %d both values", v)
+		}
+		value[v] = x
+	}
+	for i, c := range p.Clauses {
+		sat := false
+		for _, x := range c {
+			if value[x.Var()] == x {
+				sat = true
+				break
+			}
+		}
+		if !sat {
+			return fmt.Errorf("clause %d not satisfied: %v", i+1, c)
+		}
+	}
+	return nil
+}
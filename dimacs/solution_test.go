@@ -0,0 +1,104 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeSolution(t *testing.T) {
+	var model []Lit
+	for v := 1; v <= 100; v++ {
+		x := Lit(v)
+		if v%3 == 0 {
+			x = -x
+		}
+		model = append(model, x)
+	}
+	for _, sol := range []*Solution{
+		{Satisfiable, model},
+		{Satisfiable, nil},
+		{Unsatisfiable, nil},
+		{Unknown, nil},
+	} {
+		var buf bytes.Buffer
+		err := EncodeSolution(&buf, sol)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if len(line) > SolutionWidth {
+				t.Errorf("%s: line too long: %q", sol.Status, line)
+			}
+		}
+		sol2, err := DecodeSolution(&buf)
+		if err != nil {
+			t.Errorf("%s: %v", sol.Status, err)
+			continue
+		}
+		if !reflect.DeepEqual(sol2, sol) {
+			t.Errorf("%s: decoded %v (expected %v)", sol.Status, sol2, sol)
+		}
+	}
+}
+
+func TestDecodeSolution(t *testing.T) {
+	sol, err := DecodeSolution(strings.NewReader("c solved\ns SATISFIABLE\nv 1 -2\nv 3\nv 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &Solution{Satisfiable, []Lit{1, -2, 3}}
+	if !reflect.DeepEqual(sol, expect) {
+		t.Errorf("decoded %v (expected %v)", sol, expect)
+	}
+
+	tests := []struct {
+		in   string
+		kind ErrorKind
+		line int
+	}{
+		{"c nothing\n", ErrStatus, 2},
+		{"s SATISFIED\nv 0\n", ErrStatus, 1},
+		{"s UNSATISFIABLE\ns UNSATISFIABLE\n", ErrStatus, 2},
+		{"s UNSATISFIABLE\nv 1 0\n", ErrStatus, 2},
+		{"s SATISFIABLE\nv 1 -2\n", ErrTermination, 3},
+		{"s SATISFIABLE\nv 1 0 2\n", ErrTermination, 2},
+		{"s SATISFIABLE\nv 1 x 0\n", ErrLiteral, 2},
+	}
+	for i, test := range tests {
+		_, err := DecodeSolution(strings.NewReader(test.in))
+		var derr *Error
+		if !errors.As(err, &derr) || derr.Kind != test.kind || derr.Line != test.line {
+			t.Errorf("test %d: %v (expected %s error at line %d)", i, err, test.kind, test.line)
+		}
+	}
+}
+
+func TestSolution_Check(t *testing.T) {
+	p := &Problem{3, [][]Lit{{1, -2}, {2, 3}, {-1, -3}}}
+	tests := []struct {
+		sol *Solution
+		ok  bool
+	}{
+		{&Solution{Satisfiable, []Lit{1, 2, -3}}, true},
+		{&Solution{Satisfiable, []Lit{-1, -2, 3}}, true},
+		{&Solution{Satisfiable, []Lit{1, 2}}, false},
+		{&Solution{Satisfiable, []Lit{1, 2, 3}}, false},
+		{&Solution{Satisfiable, []Lit{1, 2, -3, -1}}, false},
+		{&Solution{Satisfiable, []Lit{1, 2, -3, 4}}, false},
+		{&Solution{Unsatisfiable, nil}, false},
+	}
+	for i, test := range tests {
+		err := test.sol.Check(p)
+		if (err == nil) != test.ok {
+			t.Errorf("test %d: %v", i, err)
+		}
+	}
+}
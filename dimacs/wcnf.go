@@ -0,0 +1,137 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+)
+
+// WProblem is a weighted partial MaxSAT problem.  Every hard clause must be
+// satisfied and the total weight of the unsatisfied soft clauses is
+// minimized.
+type WProblem struct {
+	NumVar int
+	Hard   [][]Lit
+	Soft   []WClause
+}
+
+// WClause is a soft clause of a WProblem.
+type WClause struct {
+	Weight int64
+	Lits   []Lit
+}
+
+// DecodeWCNFFile opens path with Open and decodes its contents using
+// DecodeWCNF.
+func DecodeWCNFFile(path string) (*WProblem, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeWCNF(f)
+}
+
+// DecodeWCNF decodes a weighted CNF problem from r.  Both the format of the
+// MaxSAT evaluations since 2022, in which hard clauses begin with 'h' and
+// there is no header, and the older format with the header "p wcnf nbvar
+// nbclause top", in which clauses with a weight of at least top are hard, are
+// accepted.  Each clause must occupy a single line.  The number of clauses is
+// not checked against the header.
+//
+//		c new format
+//		h 1 2 0
+//		3 -1 0
+//		5 -2 0
+func DecodeWCNF(r io.Reader) (*WProblem, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<30)
+	p := &WProblem{}
+	var line int
+	var header bool
+	numVar := -1
+	top := int64(math.MaxInt64)
+	for s.Scan() {
+		line++
+		b := s.Bytes()
+		fs := fields(b, 1)
+		if len(fs) == 0 || fs[0].text[0] == 'c' {
+			continue
+		}
+		if fs[0].text == "p" {
+			if header || len(p.Hard) > 0 || len(p.Soft) > 0 {
+				return nil, solutionError(ErrHeader, line, fs[0].col, "p", "unexpected problem header")
+			}
+			header = true
+			if len(fs) < 4 || len(fs) > 5 || fs[1].text != "wcnf" {
+				return nil, solutionError(ErrHeader, line, fs[0].col, string(b), "invalid weighted problem header")
+			}
+			for i, f := range fs[2:] {
+				n, err := strconv.ParseInt(f.text, 10, 64)
+				if err != nil || n < 0 || i == 0 && n > math.MaxInt32 {
+					return nil, solutionError(ErrHeader, line, f.col, f.text, "invalid weighted problem header")
+				}
+				switch i {
+				case 0:
+					numVar = int(n)
+				case 2:
+					top = n
+				}
+			}
+			continue
+		}
+
+		var w int64
+		var hard bool
+		if fs[0].text == "h" {
+			if header {
+				return nil, solutionError(ErrWeight, line, fs[0].col, "h", "hard clause marker with a problem header")
+			}
+			hard = true
+		} else {
+			var err error
+			w, err = strconv.ParseInt(fs[0].text, 10, 64)
+			if err != nil || w <= 0 {
+				return nil, solutionError(ErrWeight, line, fs[0].col, fs[0].text, "invalid clause weight")
+			}
+			hard = w >= top
+		}
+		fs = fs[1:]
+		if len(fs) == 0 || fs[len(fs)-1].text != "0" {
+			return nil, solutionError(ErrTermination, line, len(b)+1, "", "invalid clause line: missing terminating null")
+		}
+		c := make([]Lit, 0, len(fs)-1)
+		for _, f := range fs[:len(fs)-1] {
+			x, err := strconv.Atoi(f.text)
+			if err != nil || x == 0 || x < -math.MaxInt32 || x > math.MaxInt32 {
+				return nil, solutionError(ErrLiteral, line, f.col, f.text, "invalid clause line: failed to parse literal")
+			}
+			lit := Lit(x)
+			if numVar >= 0 && lit.Var() > numVar {
+				return nil, solutionError(ErrRange, line, f.col, f.text, "invalid clause line: variable outside of range")
+			}
+			if lit.Var() > p.NumVar {
+				p.NumVar = lit.Var()
+			}
+			c = append(c, lit)
+		}
+		if hard {
+			p.Hard = append(p.Hard, c)
+		} else {
+			p.Soft = append(p.Soft, WClause{w, c})
+		}
+	}
+	if s.Err() != nil {
+		return nil, s.Err()
+	}
+	if numVar > p.NumVar {
+		p.NumVar = numVar
+	}
+	return p, nil
+}
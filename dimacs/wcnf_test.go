@@ -0,0 +1,72 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dimacs
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeWCNF(t *testing.T) {
+	expect := &WProblem{
+		NumVar: 3,
+		Hard:   [][]Lit{{1, 2}, {-3}},
+		Soft:   []WClause{{3, []Lit{-1}}, {5, []Lit{-2, 3}}},
+	}
+	for _, input := range []string{
+		"c new format\nh 1 2 0\n3 -1 0\n\nh -3 0\n5 -2 3 0\n",
+		"c old format\np wcnf 3 4 10\n10 1 2 0\n3 -1 0\n11 -3 0\n5 -2 3 0\n",
+	} {
+		p, err := DecodeWCNF(strings.NewReader(input))
+		if err != nil {
+			t.Errorf("%q: %v", input, err)
+			continue
+		}
+		if !reflect.DeepEqual(p, expect) {
+			t.Errorf("%q: decoded %v (expected %v)", input, p, expect)
+		}
+	}
+
+	p, err := DecodeWCNF(strings.NewReader("p wcnf 4 1\n2 1 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.NumVar != 4 || len(p.Hard) != 0 || len(p.Soft) != 1 {
+		t.Errorf("decoded %v", p)
+	}
+}
+
+func TestDecodeWCNF_error(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		kind  ErrorKind
+		line  int
+	}{
+		{"p cnf 1 1\n1 0\n", ErrHeader, 1},
+		{"p wcnf 1\n", ErrHeader, 1},
+		{"1 1 0\np wcnf 1 1 2\n", ErrHeader, 2},
+		{"p wcnf 1 1 2\nh 1 0\n", ErrWeight, 2},
+		{"c\n0 1 0\n", ErrWeight, 2},
+		{"x 1 0\n", ErrWeight, 1},
+		{"h 1 2\n", ErrTermination, 1},
+		{"h\n", ErrTermination, 1},
+		{"h 1 x 0\n", ErrLiteral, 1},
+		{"h 1 0 2 0\n", ErrLiteral, 1},
+		{"p wcnf 1 1 2\n1 2 0\n", ErrRange, 2},
+	} {
+		_, err := DecodeWCNF(strings.NewReader(test.input))
+		var derr *Error
+		if !errors.As(err, &derr) {
+			t.Errorf("%q: unexpected error: %v", test.input, err)
+			continue
+		}
+		if derr.Kind != test.kind || derr.Line != test.line {
+			t.Errorf("%q: %s error at line %d (expected %s at line %d)", test.input, derr.Kind, derr.Line, test.kind, test.line)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+/*
+Package xzstd registers the xz and zstd formats with package dimacs, so that
+dimacs.Open decompresses files in either format and dimacs.Create compresses
+files with the extensions .xz and .zst.  The package is imported for its side
+effect.
+
+	import _ "github.com/bmatsuo/dpll/dimacs/xzstd"
+
+The formats are implemented in pure Go by github.com/ulikunitz/xz and
+github.com/klauspost/compress/zstd.  They are kept out of package dimacs so
+that the solver itself depends only on the standard library.
+*/
+package xzstd
+
+import (
+	"io"
+
+	"github.com/bmatsuo/dpll/dimacs"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+func init() {
+	dimacs.RegisterCompression("xz", ".xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, newXZReader, newXZWriter)
+	dimacs.RegisterCompression("zstd", ".zst", []byte{0x28, 0xb5, 0x2f, 0xfd}, newZstdReader, newZstdWriter)
+}
+
+func newXZReader(r io.Reader) (io.ReadCloser, error) {
+	xr, err := xz.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(xr), nil
+}
+
+func newXZWriter(w io.Writer) (io.WriteCloser, error) {
+	return xz.NewWriter(w)
+}
+
+func newZstdReader(r io.Reader) (io.ReadCloser, error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return zr.IOReadCloser(), nil
+}
+
+func newZstdWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
@@ -0,0 +1,66 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package xzstd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func TestEncodeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xzstd-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &dimacs.Problem{
+		NumVar:  3,
+		Clauses: [][]dimacs.Lit{{1, -2}, {2, 3}, {-1, -3}},
+	}
+	var plain bytes.Buffer
+	err = dimacs.EncodeProblem(&plain, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ext := range []string{".xz", ".zst"} {
+		path := filepath.Join(dir, "problem.cnf"+ext)
+		err := dimacs.EncodeFile(path, p)
+		if err != nil {
+			t.Errorf("%q: %v", ext, err)
+			continue
+		}
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(raw, plain.Bytes()) {
+			t.Errorf("%q: not compressed", ext)
+		}
+
+		// compression is detected by content rather than by name
+		renamed := filepath.Join(dir, "problem"+ext+".txt")
+		err = os.Rename(path, renamed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p2, err := dimacs.DecodeFile(renamed)
+		if err != nil {
+			t.Errorf("%q: %v", ext, err)
+			continue
+		}
+		if !reflect.DeepEqual(p2, p) {
+			t.Errorf("%q: decoded %v (expected %v)", ext, p2, p)
+		}
+	}
+}
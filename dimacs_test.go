@@ -0,0 +1,79 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func TestDecode(t *testing.T) {
+	d := New(nil)
+	ok, err := Decode(d, strings.NewReader("p cnf 3 2\n1 -2 3 0\n-1 0\n"))
+	if !ok || err != nil {
+		t.Fatalf("decode: %v %v", ok, err)
+	}
+	if d.NumVar() != 3 || d.NumClause() != 1 {
+		t.Errorf("%d vars %d clauses", d.NumVar(), d.NumClause())
+	}
+
+	// clauses spanning lines and a wrong clause count are only decoded
+	// leniently.
+	for _, input := range []string{
+		"p cnf 3 1\n1 -2\n3 0\n",
+		"p cnf 3 1\n1 -2 3 0\n-1 0\n",
+	} {
+		_, err = Decode(New(nil), strings.NewReader(input))
+		var derr *dimacs.Error
+		if !errors.As(err, &derr) {
+			t.Errorf("%q: %v", input, err)
+		}
+		ok, err = DecodeLenient(New(nil), strings.NewReader(input))
+		if !ok || err != nil {
+			t.Errorf("%q: lenient: %v %v", input, ok, err)
+		}
+	}
+
+	_, err = Decode(New(nil), strings.NewReader("p cnf 3 2\n1 -2 0\n3 -5 0\n"))
+	var derr *dimacs.Error
+	if !errors.As(err, &derr) || derr.Kind != dimacs.ErrRange || derr.Line != 3 || derr.Column != 3 {
+		t.Errorf("decode: %v", err)
+	}
+}
+
+func TestDecode_quantified(t *testing.T) {
+	for _, input := range []string{
+		"p cnf 2 1\na 1 0\ne 2 0\n1 2 0\n",
+		"p cnf 1 2\ne 1 0\n1 0\n-1 0\n",
+		"p cnf 1 0\na 1 0\n",
+	} {
+		ok, err := Decode(New(nil), strings.NewReader(input))
+		var derr *dimacs.Error
+		if ok || !errors.As(err, &derr) || derr.Kind != dimacs.ErrQuantifier || derr.Line != 2 {
+			t.Errorf("%q: %v %v", input, ok, err)
+		}
+	}
+}
+
+func TestDecode_incremental(t *testing.T) {
+	for _, input := range []string{
+		"p inccnf\n1 2 0\na -1 0\na -2 0\n",
+		"p inccnf\na -1 0\n",
+		"p inccnf\n",
+	} {
+		for _, decode := range []func(Solver, io.Reader) (bool, error){Decode, DecodeLenient} {
+			ok, err := decode(New(nil), strings.NewReader(input))
+			var derr *dimacs.Error
+			if ok || !errors.As(err, &derr) || derr.Kind != dimacs.ErrHeader || derr.Line != 1 {
+				t.Errorf("%q: %v %v", input, ok, err)
+			}
+		}
+	}
+}
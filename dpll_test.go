@@ -5,7 +5,11 @@
 
 package dpll
 
-import "testing"
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
 
 func TestSolver_Solve_sat_factoring_2_3(t *testing.T) {
 	d := New(nil)
@@ -126,3 +130,65 @@ func TestSolver_Solve_sat_factoring_5_7(t *testing.T) {
 		t.Errorf("model length: %d (!= %d)", len(model), d.NumVar()+1)
 	}
 }
+
+func TestDPLL_Conflict_assumptions(t *testing.T) {
+	d := New(nil)
+	for d.NumVar() < 5 {
+		d.NewVar(LUndef, true)
+	}
+	x1, x2, x3, x4, x5 := Literal(1, false), Literal(2, false), Literal(3, false), Literal(4, false), Literal(5, false)
+	d.AddClause(x1.Inverse(), x2)
+	d.AddClause(x2.Inverse(), x3.Inverse())
+	d.AddClause(x5.Inverse())
+
+	for _, test := range []struct {
+		assump   []Lit
+		conflict []Lit
+	}{
+		// x3 is made false by propagating the earlier assumption x1
+		{[]Lit{x1, x4, x3}, []Lit{x1.Inverse(), x3.Inverse()}},
+		{[]Lit{x4, x3, x1}, []Lit{x1.Inverse(), x3.Inverse()}},
+		// x5 is false at the top level
+		{[]Lit{x4, x5}, []Lit{x5.Inverse()}},
+	} {
+		if d.Solve(test.assump...) {
+			t.Errorf("%v: satisfiable", test.assump)
+			continue
+		}
+		conflict := append([]Lit(nil), d.Conflict()...)
+		sort.Slice(conflict, func(i, j int) bool { return conflict[i] < conflict[j] })
+		if !reflect.DeepEqual(conflict, test.conflict) {
+			t.Errorf("%v: conflict %v (expected %v)", test.assump, conflict, test.conflict)
+		}
+	}
+
+	if !d.Solve(x1, x4) {
+		t.Fatalf("unsatisfiable")
+	}
+	if d.Conflict() != nil {
+		t.Errorf("conflict after a model: %v", d.Conflict())
+	}
+}
+
+func TestDPLL_Simplify_props(t *testing.T) {
+	d := New(nil)
+	x := Literal(d.NewVar(LUndef, true), false)
+	y := Literal(d.NewVar(LUndef, true), false)
+	z := Literal(d.NewVar(LUndef, true), false)
+	d.AddClause(x, y)
+	if !d.Simplify() {
+		t.Fatalf("unsatisfiable")
+	}
+
+	// simplification waits for as many propagations as there are literals
+	d.AddClause(x)
+	d.Simplify()
+	if d.NumClause() != 1 {
+		t.Errorf("clauses: %d (expected 1)", d.NumClause())
+	}
+	d.AddClause(z)
+	d.Simplify()
+	if d.NumClause() != 0 {
+		t.Errorf("clauses: %d (expected 0)", d.NumClause())
+	}
+}
@@ -51,6 +51,15 @@ func (q *elimQueue) Push(v Var) {
 	q.h().Push(v)
 }
 
+// clone returns a copy of q ordered by the occurrence counts numocc.
+func (q *elimQueue) clone(numocc *[]int) *elimQueue {
+	return &elimQueue{
+		numocc: numocc,
+		index:  append([]int(nil), q.index...),
+		vars:   append([]Var(nil), q.vars...),
+	}
+}
+
 // minCostHeap is a heap.Interface that prioritizes variables by minimizing
 // literal occurance uniformity, maximizing bias between negated and positive
 // literal occurrences for a variable.
@@ -0,0 +1,272 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// GateKind identifies a kind of gate definition found during variable
+// elimination.
+type GateKind int
+
+// Gate kinds recognized by the Simp solver.
+const (
+	GateNone  GateKind = iota
+	GateEquiv          // x = a
+	GateAnd            // x = a1 & a2 & ... & an
+	GateXor            // x = a ^ b
+	GateITE            // x = c ? t : e
+	numGateKind
+)
+
+var gateKindStrings = []string{
+	GateNone:  "NONE",
+	GateEquiv: "EQUIV",
+	GateAnd:   "AND",
+	GateXor:   "XOR",
+	GateITE:   "ITE",
+}
+
+// String returns the name of k.
+func (k GateKind) String() string {
+	if k < 0 || k >= numGateKind {
+		return "INVALID"
+	}
+	return gateKindStrings[k]
+}
+
+// gate is a definition of a variable found in its occurrence lists.
+type gate struct {
+	kind   GateKind
+	out    Lit       // the defined literal
+	in     []Lit     // gate inputs
+	clause []*Clause // clauses encoding the definition
+}
+
+func (g *gate) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%v = %v(", g.out, g.kind)
+	for i, p := range g.in {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(p.String())
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// contains returns true if c is one of the clauses defining g.
+func (g *gate) contains(c *Clause) bool {
+	for _, gc := range g.clause {
+		if gc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// findGate looks for a definition of v in the clauses containing v.  pos and
+// neg must contain the clauses in which v occurs positively and negatively
+// respectively.  If no definition is found findGate returns nil.
+func (s *Simp) findGate(v Var, pos, neg []*Clause) *gate {
+	for _, x := range []Lit{Literal(v, false), Literal(v, true)} {
+		if g := findAnd(x, pos, neg); g != nil {
+			return g
+		}
+	}
+	if g := findXor(v, pos, neg); g != nil {
+		return g
+	}
+	for _, x := range []Lit{Literal(v, false), Literal(v, true)} {
+		if g := findITE(x, pos, neg); g != nil {
+			return g
+		}
+	}
+	return nil
+}
+
+// gateSides returns the clauses containing x and those containing ~x.
+func gateSides(x Lit, pos, neg []*Clause) (with, without []*Clause) {
+	if x.IsNeg() {
+		return neg, pos
+	}
+	return pos, neg
+}
+
+// findAnd looks for a definition x = a1 & ... & an, encoded as binary
+// clauses (~x | ai) and the clause (x | ~a1 | ... | ~an).  When n is 1 the
+// definition is an equivalence.
+func findAnd(x Lit, pos, neg []*Clause) *gate {
+	with, without := gateSides(x, pos, neg)
+
+	binary := make(map[Lit]*Clause)
+	for _, c := range without {
+		if c.Len() == 2 {
+			binary[otherLit(c, x.Inverse())] = c
+		}
+	}
+	if len(binary) == 0 {
+		return nil
+	}
+
+nextClause:
+	for _, c := range with {
+		g := &gate{kind: GateAnd, out: x, clause: []*Clause{c}}
+		for _, q := range c.Lit {
+			if q == x {
+				continue
+			}
+			bc, ok := binary[q.Inverse()]
+			if !ok {
+				continue nextClause
+			}
+			g.in = append(g.in, q.Inverse())
+			g.clause = append(g.clause, bc)
+		}
+		if len(g.in) == 1 {
+			g.kind = GateEquiv
+		}
+		return g
+	}
+	return nil
+}
+
+// findXor looks for a definition x = a ^ b, encoded as four ternary clauses
+// over the variables of x, a and b.
+func findXor(v Var, pos, neg []*Clause) *gate {
+	all := make([]*Clause, 0, len(pos)+len(neg))
+	all = append(all, pos...)
+	all = append(all, neg...)
+	for _, c := range all {
+		if c.Len() != 3 {
+			continue
+		}
+		var p, a, b Lit
+		for _, q := range c.Lit {
+			switch {
+			case q.Var() == v:
+				p = q
+			case a.IsUndef():
+				a = q
+			default:
+				b = q
+			}
+		}
+
+		// the other clauses negate exactly two literals of c
+		g := &gate{
+			kind: GateXor,
+			out:  p.Inverse(),
+			in:   []Lit{a, b},
+			clause: []*Clause{
+				c,
+				findClause(all, p.Inverse(), a.Inverse(), b),
+				findClause(all, p.Inverse(), a, b.Inverse()),
+				findClause(all, p, a.Inverse(), b.Inverse()),
+			},
+		}
+		if g.clause[1] != nil && g.clause[2] != nil && g.clause[3] != nil {
+			return g
+		}
+	}
+	return nil
+}
+
+// findITE looks for a definition x = c ? t : e, encoded as the clauses
+// (~x | ~c | t), (~x | c | e), (x | ~c | ~t) and (x | c | ~e).
+func findITE(x Lit, pos, neg []*Clause) *gate {
+	with, without := gateSides(x, pos, neg)
+	for i, c1 := range without {
+		if c1.Len() != 3 {
+			continue
+		}
+		for _, c2 := range without[i+1:] {
+			if c2.Len() != 3 {
+				continue
+			}
+			for _, q := range c1.Lit {
+				if q == x.Inverse() || !containsLit(c2, q.Inverse()) {
+					continue
+				}
+				// c1 and c2 may be in either order
+				for _, cond := range []Lit{q.Inverse(), q} {
+					ct, ce := c1, c2
+					if cond == q {
+						ct, ce = c2, c1
+					}
+					t := otherLit2(ct, x.Inverse(), cond.Inverse())
+					e := otherLit2(ce, x.Inverse(), cond)
+					if t.SharesVar(e) {
+						continue
+					}
+					g := &gate{
+						kind: GateITE,
+						out:  x,
+						in:   []Lit{cond, t, e},
+						clause: []*Clause{
+							ct,
+							ce,
+							findClause(with, x, cond.Inverse(), t.Inverse()),
+							findClause(with, x, cond, e.Inverse()),
+						},
+					}
+					if g.clause[2] != nil && g.clause[3] != nil {
+						return g
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findClause returns a clause in cs containing exactly the literals ps.
+func findClause(cs []*Clause, ps ...Lit) *Clause {
+nextClause:
+	for _, c := range cs {
+		if c.Len() != len(ps) {
+			continue
+		}
+		for _, p := range ps {
+			if !containsLit(c, p) {
+				continue nextClause
+			}
+		}
+		return c
+	}
+	return nil
+}
+
+func containsLit(c *Clause, p Lit) bool {
+	for _, q := range c.Lit {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// otherLit returns the literal of the binary clause c which is not p.
+func otherLit(c *Clause, p Lit) Lit {
+	if c.Lit[0] == p {
+		return c.Lit[1]
+	}
+	return c.Lit[0]
+}
+
+// otherLit2 returns the literal of the ternary clause c which is neither p1
+// nor p2.
+func otherLit2(c *Clause, p1, p2 Lit) Lit {
+	for _, q := range c.Lit {
+		if q != p1 && q != p2 {
+			return q
+		}
+	}
+	return LitUndef
+}
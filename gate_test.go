@@ -0,0 +1,121 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"testing"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func TestSimp_findGate(t *testing.T) {
+	tests := []struct {
+		clauses [][]int
+		kind    GateKind
+		out     int
+		in      []int
+	}{
+		{[][]int{{-1, 2}, {1, -2}}, GateEquiv, 1, []int{2}},
+		{[][]int{{-1, 2}, {-1, 3}, {1, -2, -3}, {1, 4}}, GateAnd, 1, []int{2, 3}},
+		{[][]int{{1, 2}, {1, 3}, {-1, -2, -3}}, GateAnd, -1, []int{2, 3}},
+		{[][]int{{-1, 2, 3}, {-1, -2, -3}, {1, -2, 3}, {1, 2, -3}}, GateXor, -1, []int{-2, 3}},
+		{[][]int{{-1, -2, 3}, {-1, 2, 4}, {1, -2, -3}, {1, 2, -4}}, GateITE, 1, []int{2, 3, 4}},
+		{[][]int{{-1, 2, 3}, {1, -2, 3}}, GateNone, 0, nil},
+	}
+
+	for i, test := range tests {
+		s := NewSimp(nil, nil)
+		for v := 0; v < 4; v++ {
+			s.NewVar(LUndef, true)
+		}
+		for _, c := range test.clauses {
+			var ps []Lit
+			for _, x := range c {
+				ps = append(ps, LiteralInt(x))
+			}
+			s.AddClause(ps...)
+		}
+
+		var pos, neg []*Clause
+		for _, c := range s.occurs.Lookup(1) {
+			if containsLit(c, LiteralInt(1)) {
+				pos = append(pos, c)
+			} else {
+				neg = append(neg, c)
+			}
+		}
+
+		g := s.findGate(1, pos, neg)
+		if g == nil {
+			if test.kind != GateNone {
+				t.Errorf("test %d: no gate found (expected %v)", i, test.kind)
+			}
+			continue
+		}
+		if g.kind != test.kind {
+			t.Errorf("test %d: kind %v (!= %v)", i, g.kind, test.kind)
+			continue
+		}
+		if g.out != LiteralInt(test.out) {
+			t.Errorf("test %d: out %v (!= %v)", i, g.out, LiteralInt(test.out))
+		}
+		if len(g.in) != len(test.in) {
+			t.Errorf("test %d: in %v (!= %v)", i, g.in, test.in)
+			continue
+		}
+		for j := range g.in {
+			if g.in[j] != LiteralInt(test.in[j]) {
+				t.Errorf("test %d: in %v (!= %v)", i, g.in, test.in)
+				break
+			}
+		}
+	}
+}
+
+func TestSimp_Eliminate_gates(t *testing.T) {
+	tests := []struct {
+		path string
+		sat  bool
+	}{
+		{"testdata/factoring_2_3.cnf", true},
+		{"testdata/factoring_2_3_UNSAT.cnf", false},
+		{"testdata/factoring_3_5.cnf", true},
+		{"testdata/factoring_3_5_UNSAT.cnf", false},
+		{"testdata/factoring_5_7.cnf", true},
+	}
+	for _, test := range tests {
+		p, err := dimacs.DecodeFile(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ngates, nclauses [2]int
+		for i, nogates := range []bool{true, false} {
+			s := NewSimp(nil, &SimpOpt{NoGates: nogates})
+			addProblem(s, p)
+			ok := s.Eliminate(true)
+			for _, n := range s.ngates {
+				ngates[i] += n
+			}
+			nclauses[i] = s.NumClause()
+			if ok {
+				ok = s.Solve()
+			}
+			if ok != test.sat {
+				t.Errorf("%s (gates %v): sat %v (!= %v)", test.path, !nogates, ok, test.sat)
+			}
+			if ok {
+				checkModel(t, test.path, p, s.d.Model())
+			}
+		}
+		if ngates[0] != 0 {
+			t.Errorf("%s: gates used when disabled", test.path)
+		}
+		if test.sat && ngates[1] == 0 {
+			t.Errorf("%s: no gates found", test.path)
+		}
+		t.Logf("%s: %d gates, %d clauses (%d without gates)", test.path, ngates[1], nclauses[1], nclauses[0])
+	}
+}
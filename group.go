@@ -0,0 +1,175 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Group identifies a set of clauses which can be disabled or deleted
+// together.  Groups are implemented with activation literals.  Each clause in
+// a group is extended with the negation of the group's activation literal and
+// the activation literals of enabled groups are passed to the search as
+// assumptions ahead of any given by the caller.
+type Group int
+
+type clauseGroup struct {
+	act      Lit // assumed true while the group is enabled
+	disabled bool
+	deleted  bool
+}
+
+// NewGroup creates an empty clause group.  The group is enabled.
+func (d *DPLL) NewGroup() Group {
+	return d.newGroup(d.NewVar(LUndef, false))
+}
+
+// AddClauseToGroup adds a clause which is only enforced while g is enabled.
+func (d *DPLL) AddClauseToGroup(g Group, ps ...Lit) bool {
+	return d.AddClause(d.groupClause(g, ps)...)
+}
+
+// EnableGroup enables the clauses of g in subsequent calls to Solve.
+func (d *DPLL) EnableGroup(g Group) {
+	d.group(g).disabled = false
+}
+
+// DisableGroup disables the clauses of g in subsequent calls to Solve.  The
+// clauses may be enabled again with EnableGroup.
+func (d *DPLL) DisableGroup(g Group) {
+	d.group(g).disabled = true
+}
+
+// DeleteGroup permanently removes the clauses of g along with any learnt
+// clauses derived from them.  The group cannot be used after DeleteGroup is
+// called.
+func (d *DPLL) DeleteGroup(g Group) {
+	d.ReleaseVar(d.deleteGroup(g).Inverse())
+}
+
+// ConflictGroups returns the enabled groups whose clauses participated in the
+// final conflict if the last call to Solve could not find a model.  Groups
+// are not reported by Conflict.
+func (d *DPLL) ConflictGroups() []Group {
+	return d.conflictGroups
+}
+
+func (d *DPLL) newGroup(v Var) Group {
+	g := Group(len(d.groups))
+	d.groups = append(d.groups, clauseGroup{act: Literal(v, false)})
+	if d.groupOf == nil {
+		d.groupOf = make(map[Var]Group)
+	}
+	d.groupOf[v] = g
+	return g
+}
+
+func (d *DPLL) group(g Group) *clauseGroup {
+	if g < 0 || int(g) >= len(d.groups) {
+		panic(fmt.Sprintf("invalid group: %d", g))
+	}
+	if d.groups[g].deleted {
+		panic(fmt.Sprintf("group deleted: %d", g))
+	}
+	return &d.groups[g]
+}
+
+// groupClause returns a copy of ps extended with the negation of the
+// activation literal of g.
+func (d *DPLL) groupClause(g Group, ps []Lit) []Lit {
+	act := d.group(g).act
+	qs := make([]Lit, len(ps), len(ps)+1)
+	copy(qs, ps)
+	return append(qs, act.Inverse())
+}
+
+// deleteGroup marks g as deleted and removes learnt clauses depending on it.
+// deleteGroup returns the activation literal of g, which the caller must
+// release.
+func (d *DPLL) deleteGroup(g Group) Lit {
+	grp := d.group(g)
+	grp.deleted = true
+	delete(d.groupOf, grp.act.Var())
+
+	// activation literals only occur negatively in clauses
+	for _, c := range d.learnt {
+		if !isRemoved(c) && containsLit(c, grp.act.Inverse()) {
+			d.removeClause(c)
+		}
+	}
+	d.checkGarbage()
+
+	return grp.act
+}
+
+// groupAssumptions returns the activation literals of enabled groups followed
+// by assump.
+func (d *DPLL) groupAssumptions(assump []Lit) []Lit {
+	if len(d.groups) == 0 {
+		return assump
+	}
+	var ps []Lit
+	for _, grp := range d.groups {
+		if !grp.disabled && !grp.deleted {
+			ps = append(ps, grp.act)
+		}
+	}
+	return append(ps, assump...)
+}
+
+// splitGroupConflict separates the negated activation literals in conflict
+// from the caller's assumptions.
+func (d *DPLL) splitGroupConflict(conflict []Lit) (ps []Lit, gs []Group) {
+	for _, p := range conflict {
+		if g, ok := d.groupOf[p.Var()]; ok {
+			gs = append(gs, g)
+		} else {
+			ps = append(ps, p)
+		}
+	}
+	sort.Sort(groupSlice(gs))
+	return ps, gs
+}
+
+type groupSlice []Group
+
+func (gs groupSlice) Len() int           { return len(gs) }
+func (gs groupSlice) Less(i, j int) bool { return gs[i] < gs[j] }
+func (gs groupSlice) Swap(i, j int)      { gs[i], gs[j] = gs[j], gs[i] }
+
+// NewGroup behaves like DPLL.NewGroup.  The activation variable of the group
+// is frozen.
+func (s *Simp) NewGroup() Group {
+	v := s.NewVar(LUndef, false)
+	s.SetFrozen(v, true)
+	return s.d.newGroup(v)
+}
+
+// AddClauseToGroup behaves like DPLL.AddClauseToGroup.
+func (s *Simp) AddClauseToGroup(g Group, ps ...Lit) bool {
+	return s.AddClause(s.d.groupClause(g, ps)...)
+}
+
+// EnableGroup behaves like DPLL.EnableGroup.
+func (s *Simp) EnableGroup(g Group) {
+	s.d.EnableGroup(g)
+}
+
+// DisableGroup behaves like DPLL.DisableGroup.
+func (s *Simp) DisableGroup(g Group) {
+	s.d.DisableGroup(g)
+}
+
+// DeleteGroup behaves like DPLL.DeleteGroup.
+func (s *Simp) DeleteGroup(g Group) {
+	s.ReleaseVar(s.d.deleteGroup(g).Inverse())
+}
+
+// ConflictGroups behaves like DPLL.ConflictGroups.
+func (s *Simp) ConflictGroups() []Group {
+	return s.d.ConflictGroups()
+}
@@ -0,0 +1,152 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"reflect"
+	"testing"
+)
+
+type groupSolver interface {
+	Solver
+	NewGroup() Group
+	AddClauseToGroup(g Group, ps ...Lit) bool
+	EnableGroup(g Group)
+	DisableGroup(g Group)
+	DeleteGroup(g Group)
+	Model() []LBool
+	Conflict() []Lit
+	ConflictGroups() []Group
+	Okay() bool
+}
+
+func TestDPLL_groups(t *testing.T) {
+	testGroups(t, New(nil))
+}
+
+func TestSimp_groups(t *testing.T) {
+	testGroups(t, NewSimp(nil, nil))
+}
+
+func testGroups(t *testing.T, s groupSolver) {
+	for i := 0; i < 3; i++ {
+		s.NewVar(LUndef, true)
+	}
+	x, y, z := LiteralInt(1), LiteralInt(2), LiteralInt(3)
+	s.AddClause(x, y)
+
+	g1 := s.NewGroup()
+	g2 := s.NewGroup()
+	g3 := s.NewGroup()
+	s.AddClauseToGroup(g1, x.Inverse())
+	s.AddClauseToGroup(g2, y.Inverse())
+	s.AddClauseToGroup(g3, z)
+
+	if s.Solve() {
+		t.Fatalf("satisfiable with all groups enabled")
+	}
+	if gs := s.ConflictGroups(); !reflect.DeepEqual(gs, []Group{g1, g2}) {
+		t.Errorf("conflict groups: %v", gs)
+	}
+	if len(s.Conflict()) != 0 {
+		t.Errorf("conflict: %v", s.Conflict())
+	}
+
+	// user assumptions are reported separately from groups
+	s.DisableGroup(g1)
+	if s.Solve(x.Inverse()) {
+		t.Fatalf("satisfiable assuming %v", x.Inverse())
+	}
+	if gs := s.ConflictGroups(); !reflect.DeepEqual(gs, []Group{g2}) {
+		t.Errorf("conflict groups: %v", gs)
+	}
+	if c := s.Conflict(); !reflect.DeepEqual(c, []Lit{x}) {
+		t.Errorf("conflict: %v", c)
+	}
+	s.EnableGroup(g1)
+
+	s.DisableGroup(g2)
+	if !s.Solve() {
+		t.Fatalf("unsatisfiable with %v disabled", g2)
+	}
+	model := s.Model()
+	if !model[y.Var()].IsTrue() || !model[z.Var()].IsTrue() {
+		t.Errorf("model: %v", model)
+	}
+	if s.ConflictGroups() != nil {
+		t.Errorf("conflict groups: %v", s.ConflictGroups())
+	}
+
+	s.DeleteGroup(g1)
+	s.EnableGroup(g2)
+	if !s.Solve() {
+		t.Fatalf("unsatisfiable with %v deleted", g1)
+	}
+	model = s.Model()
+	if !model[x.Var()].IsTrue() || !model[y.Var()].IsFalse() {
+		t.Errorf("model: %v", model)
+	}
+	if !s.Okay() {
+		t.Errorf("not ok")
+	}
+}
+
+func TestDPLL_DeleteGroup_learnt(t *testing.T) {
+	d := New(nil)
+	for i := 0; i < 3; i++ {
+		d.NewVar(LUndef, true)
+	}
+	x, y, z := LiteralInt(1), LiteralInt(2), LiteralInt(3)
+	g := d.NewGroup()
+	d.AddClauseToGroup(g, x, y)
+	d.AddClauseToGroup(g, x, y.Inverse())
+	d.AddClause(x.Inverse(), z)
+
+	// a learnt clause depending on g
+	act := d.groups[g].act
+	c := d.newClause([]Lit{act.Inverse(), x}, true)
+	d.learnt = append(d.learnt, c)
+	d.attachClause(c)
+
+	d.DeleteGroup(g)
+	if !isRemoved(c) {
+		t.Errorf("learnt clause not removed: %v", c.Lit)
+	}
+	if !d.Solve(x.Inverse()) {
+		t.Errorf("unsatisfiable with group deleted")
+	}
+}
+
+func TestDPLL_group_deleted(t *testing.T) {
+	d := New(nil)
+	g := d.NewGroup()
+	d.DeleteGroup(g)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic")
+		}
+	}()
+	d.EnableGroup(g)
+}
+
+func TestDPLL_DeleteGroup_reuse(t *testing.T) {
+	d := New(nil)
+	x := d.NewVar(LUndef, true)
+	y := d.NewVar(LUndef, true)
+	d.AddClause(Literal(x, false), Literal(y, false))
+	for i := 0; i < 10; i++ {
+		g := d.NewGroup()
+		d.AddClauseToGroup(g, Literal(x, true))
+		if !d.Solve() {
+			t.Fatalf("unsat")
+		}
+		d.DeleteGroup(g)
+	}
+	// released activation variables are reused after the next solve
+	if d.NumVar() > 4 {
+		t.Errorf("variables: %d", d.NumVar())
+	}
+}
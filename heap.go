@@ -39,6 +39,15 @@ func (q *activityQueue) Push(v Var) {
 	(*maxActiveHeap)(q).Push(v)
 }
 
+// clone returns a copy of q ordered by activity.
+func (q *activityQueue) clone(activity *[]float64) *activityQueue {
+	return &activityQueue{
+		act:   activity,
+		index: append([]int(nil), q.index...),
+		vars:  append([]Var(nil), q.vars...),
+	}
+}
+
 // maxActiveHeap is a heap.Interface that prioritizes variables by max
 // activity.
 type maxActiveHeap struct {
@@ -0,0 +1,130 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import "log"
+
+// inprocess is called by the DPLL solver at restarts.  Every
+// InprocessInterval conflicts the occurrence lists are rebuilt for the
+// original clauses and subsumption, strengthening and variable elimination
+// are run again.  The number of simplification steps allowed is
+// InprocessEffort times the number of propagations made since the last round.
+// inprocess returns false if the clause set was found to be unsatisfiable.
+func (s *Simp) inprocess() bool {
+	if !s.Inprocess || s.d.nconflicts < s.inprocessConf {
+		return true
+	}
+	if s.d.decisionLevel() != 0 {
+		panic("non-root decision level")
+	}
+	s.ninprocess++
+	s.inprocessConf = s.d.nconflicts + uint64(s.InprocessInterval)
+
+	// assumptions must not be eliminated
+	var extraFrozen []Var
+	for _, p := range s.d.assumptions {
+		if !s.frozen[p.Var()] {
+			s.frozen[p.Var()] = true
+			extraFrozen = append(extraFrozen, p.Var())
+		}
+	}
+
+	turnOff := !s.useSimp
+	if turnOff {
+		s.rebuildOccurs()
+	} else {
+		s.rebuildElimHeap()
+	}
+
+	nelim := s.nelimvars
+	nclause := s.d.NumClause()
+	steps := s.nmerge + s.nsubcheck
+	s.simpBudget = steps + int(s.InprocessEffort*float64(s.d.npropogations-s.inprocessProp))
+	ok := s.Eliminate(turnOff)
+	s.simpBudget = -1
+	s.inprocessProp = s.d.npropogations
+
+	for _, v := range extraFrozen {
+		s.frozen[v] = false
+	}
+
+	if ok {
+		s.removeEliminatedLearnt()
+	}
+
+	if s.d.Verbosity >= 1 {
+		log.Printf("|  Inprocessing %4d:  %8d eliminated vars %8d removed clauses %8d steps |",
+			s.ninprocess, s.nelimvars-nelim, nclause-s.d.NumClause(), s.nmerge+s.nsubcheck-steps)
+	}
+
+	return ok
+}
+
+func (s *Simp) withinSimpBudget() bool {
+	s.d.pollTerminate()
+	return (s.simpBudget < 0 || s.nmerge+s.nsubcheck < s.simpBudget) && !s.d.timeExceeded()
+}
+
+// rebuildOccurs turns simplification back on after Eliminate(true) has
+// released the occurrence lists, indexing all original clauses.
+func (s *Simp) rebuildOccurs() {
+	n := s.d.NumVar()
+
+	s.useSimp = true
+	s.d.removeSat = false
+	s.occurs = newClauseOccLists()
+	s.occurs.extend(n)
+	s.numOcc = make([]int, 2*(n+1))
+	s.touched = make([]bool, n+1)
+	s.numTouched = 0
+	s.subQueue = newClauseQueue(1)
+	s.bwdsubAssigns = 0
+	s.elimHeap = newElimQueue(&s.numOcc)
+
+	for _, c := range s.d.clauses {
+		if isRemoved(c) {
+			continue
+		}
+		s.subQueue.Insert(c)
+		for _, p := range c.Lit {
+			s.occurs.Push(p.Var(), c)
+			s.numOcc[p]++
+			s.touched[p.Var()] = true
+			s.numTouched++
+		}
+	}
+
+	s.rebuildElimHeap()
+}
+
+// rebuildElimHeap places all variables which may be eliminated on the
+// elimination heap.
+func (s *Simp) rebuildElimHeap() {
+	var vs []Var
+	for v := Var(1); int(v) <= s.d.NumVar(); v++ {
+		if !s.frozen[v] && !s.IsEliminated(v) && s.d.Value(v).IsUndef() {
+			vs = append(vs, v)
+		}
+	}
+	s.elimHeap.Rebuild(vs)
+}
+
+// removeEliminatedLearnt removes learnt clauses which contain eliminated
+// variables.
+func (s *Simp) removeEliminatedLearnt() {
+	for _, c := range s.d.learnt {
+		if isRemoved(c) {
+			continue
+		}
+		for _, p := range c.Lit {
+			if s.IsEliminated(p.Var()) {
+				s.d.removeClause(c)
+				break
+			}
+		}
+	}
+	s.d.checkGarbage()
+}
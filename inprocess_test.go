@@ -0,0 +1,229 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func TestSimp_Solve_inprocess(t *testing.T) {
+	tests := []struct {
+		path string
+		sat  bool
+	}{
+		{"testdata/factoring_2_3.cnf", true},
+		{"testdata/factoring_2_3_UNSAT.cnf", false},
+		{"testdata/factoring_3_5.cnf", true},
+		{"testdata/factoring_3_5_UNSAT.cnf", false},
+		{"testdata/factoring_5_7.cnf", true},
+	}
+	for _, test := range tests {
+		p, err := dimacs.DecodeFile(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := NewSimp(&Opt{RestartFirst: 10}, &SimpOpt{
+			Inprocess:         true,
+			InprocessInterval: 20,
+			InprocessEffort:   10,
+		})
+		_, err = DecodeFile(s, test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Eliminate(true) {
+			if test.sat {
+				t.Errorf("%s: unsatisfiable after elimination", test.path)
+			}
+			continue
+		}
+		sat := s.Solve()
+		if sat != test.sat {
+			t.Errorf("%s: sat %v (!= %v)", test.path, sat, test.sat)
+		}
+		if sat {
+			checkModel(t, test.path, p, s.d.Model())
+		}
+	}
+}
+
+func TestSimp_Solve_inprocess_random(t *testing.T) {
+	for seed := int64(1); seed <= 4; seed++ {
+		p := randomProblem(seed, 120, 510)
+
+		d := New(nil)
+		addProblem(d, p)
+		sat := d.Solve()
+
+		s := NewSimp(&Opt{RestartFirst: 10}, &SimpOpt{
+			Inprocess:         true,
+			InprocessInterval: 100,
+		})
+		addProblem(s, p)
+		if !s.Eliminate(true) {
+			if sat {
+				t.Errorf("seed %d: unsatisfiable after elimination", seed)
+			}
+			continue
+		}
+		if s.Solve() != sat {
+			t.Errorf("seed %d: sat %v (!= %v)", seed, !sat, sat)
+		}
+		if sat {
+			checkModel(t, "random", p, s.d.Model())
+		}
+	}
+}
+
+// TestSimp_Solve_inprocess_crosscheck compares incremental solves with
+// inprocessing against a plain solver.  Variables added between solves may be
+// eliminated by later rounds, while some earlier variables are frozen.
+func TestSimp_Solve_inprocess_crosscheck(t *testing.T) {
+	var rounds, inprocessElim int
+	for seed := int64(1); seed <= 12; seed++ {
+		for _, turnOff := range []bool{false, true} {
+			r := rand.New(rand.NewSource(seed))
+			p := randomProblem(seed, 100, 420)
+			ref := New(nil)
+			addProblem(ref, p)
+			s := NewSimp(&Opt{RestartFirst: 10}, &SimpOpt{
+				Inprocess:         true,
+				InprocessInterval: 20,
+				InprocessEffort:   10,
+				Grow:              100,
+			})
+			addProblem(s, p)
+			ok := s.Eliminate(turnOff)
+			nelim := s.nelimvars
+			if !ok && ref.Solve() {
+				t.Errorf("seed %d: unsatisfiable after elimination", seed)
+				continue
+			}
+			live := func() Lit {
+				for {
+					v := Var(r.Intn(s.NumVar()) + 1)
+					if !s.IsEliminated(v) {
+						return Literal(v, r.Intn(2) == 0)
+					}
+				}
+			}
+
+			var frozen []Var
+			for i := 0; ok && i < 4; i++ {
+				assump := []Lit{live(), live(), live()}
+				sat := ref.Solve(assump...)
+				if s.Solve(assump...) != sat {
+					t.Errorf("seed %d turnOff %v solve %d: sat %v (!= %v)", seed, turnOff, i, !sat, sat)
+					break
+				}
+				if sat {
+					model := s.Model()
+					checkModel(t, "crosscheck", p, model)
+					for _, q := range assump {
+						if !model[q.Var()].Xor(q.IsNeg()).IsTrue() {
+							t.Errorf("seed %d turnOff %v solve %d: assumption %v false", seed, turnOff, i, q)
+						}
+					}
+				}
+				for _, v := range frozen {
+					if s.IsEliminated(v) {
+						t.Fatalf("seed %d turnOff %v solve %d: frozen variable %v eliminated", seed, turnOff, i, v)
+					}
+				}
+				for _, c := range s.d.learnt {
+					for _, q := range c.Lit {
+						if s.IsEliminated(q.Var()) {
+							t.Fatalf("seed %d turnOff %v solve %d: learnt clause with eliminated variable %v", seed, turnOff, i, q.Var())
+						}
+					}
+				}
+
+				// define a new variable as the disjunction of two others, one
+				// of which is frozen
+				a, b := live(), live()
+				s.SetFrozen(a.Var(), true)
+				frozen = append(frozen, a.Var())
+				x := Literal(s.NewVar(LUndef, true), false)
+				ref.NewVar(LUndef, true)
+				p.NumVar++
+				for _, c := range [][]Lit{{x.Inverse(), a, b}, {x, a.Inverse()}, {x, b.Inverse()}} {
+					ok = s.AddClause(c...) && ok
+					ref.AddClause(c...)
+					dc := make([]dimacs.Lit, len(c))
+					for j, q := range c {
+						dc[j] = dimacs.Lit(q.Var())
+						if q.IsNeg() {
+							dc[j] = -dc[j]
+						}
+					}
+					p.Clauses = append(p.Clauses, dc)
+				}
+			}
+			rounds += s.ninprocess
+			inprocessElim += s.nelimvars - nelim
+		}
+	}
+	if rounds == 0 || inprocessElim == 0 {
+		t.Errorf("%d rounds of inprocessing eliminated %d variables", rounds, inprocessElim)
+	}
+}
+
+// randomProblem returns a random 3-CNF problem.
+func randomProblem(seed int64, nvar, nclause int) *dimacs.Problem {
+	r := rand.New(rand.NewSource(seed))
+	p := &dimacs.Problem{NumVar: nvar}
+	for len(p.Clauses) < nclause {
+		var c []dimacs.Lit
+	nextLit:
+		for len(c) < 3 {
+			x := dimacs.Lit(r.Intn(nvar) + 1)
+			for _, y := range c {
+				if y.Var() == x.Var() {
+					continue nextLit
+				}
+			}
+			if r.Intn(2) == 0 {
+				x = -x
+			}
+			c = append(c, x)
+		}
+		p.Clauses = append(p.Clauses, c)
+	}
+	return p
+}
+
+// addProblem adds the variables and clauses of p to s.
+func addProblem(s Solver, p *dimacs.Problem) {
+	for s.NumVar() < p.NumVar {
+		s.NewVar(LUndef, true)
+	}
+	for _, c := range p.Clauses {
+		ps := make([]Lit, len(c))
+		for i, x := range c {
+			ps[i] = LiteralInt(int(x))
+		}
+		s.AddClause(ps...)
+	}
+}
+
+// checkModel reports an error if model does not satisfy every clause in p.
+func checkModel(t *testing.T, name string, p *dimacs.Problem, model []LBool) {
+	for i, c := range p.Clauses {
+		sat := false
+		for _, x := range c {
+			if model[LiteralInt(int(x)).Var()].Xor(x.Neg()).IsTrue() {
+				sat = true
+				break
+			}
+		}
+		if !sat {
+			t.Errorf("%s: clause %d not satisfied: %v", name, i, c)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+/*
+Package ipasir adapts the dpll solvers to the IPASIR incremental SAT solver
+interface.  Literals are nonzero integers as in DIMACS.  Clauses are added one
+literal at a time, terminated by 0.  Assumptions only hold for the next call
+to Solve.
+
+The C shared library in cmd/ipasir-dpll exports the IPASIR functions using the
+adapter in this package.
+*/
+package ipasir
+
+import "github.com/bmatsuo/dpll"
+
+// Results of IPASIR.Solve.
+const (
+	Unknown       = 0
+	Satisfiable   = 10
+	Unsatisfiable = 20
+)
+
+const signature = "dpll-go"
+
+// IPASIR is the incremental solver interface.  The methods correspond to the
+// C functions of the same name with the ipasir_ prefix.
+type IPASIR interface {
+	// Signature returns the name and version of the solver.
+	Signature() string
+
+	// Add adds lit to the clause under construction.  If lit is 0 the clause
+	// is added to the solver.
+	Add(lit int)
+
+	// Assume adds an assumption for the next call to Solve.
+	Assume(lit int)
+
+	// Solve returns Satisfiable, Unsatisfiable, or Unknown if the search was
+	// terminated.  Assumptions are cleared.
+	Solve() int
+
+	// Val returns lit if lit is true in the model found by the last call to
+	// Solve, -lit if it is false and 0 if either value may be used.
+	Val(lit int) int
+
+	// Failed returns true if the assumption lit was used to prove
+	// unsatisfiability in the last call to Solve.
+	Failed(lit int) bool
+
+	// SetTerminate sets a function polled during Solve by the goroutine
+	// running the search.  Search is terminated when fn returns true.  A nil
+	// fn removes the callback.
+	SetTerminate(fn func() bool)
+
+	// SetLearn sets a function receiving learnt clauses with at most maxLen
+	// literals.  A nil fn removes the callback.
+	SetLearn(maxLen int, fn func(clause []int))
+}
+
+// Backend is a dpll solver which can be adapted to IPASIR.  Both *dpll.DPLL
+// and *dpll.Simp implement Backend.
+type Backend interface {
+	dpll.Solver
+	Model() []dpll.LBool
+	Conflict() []dpll.Lit
+	SetTerminate(fn func() bool)
+}
+
+// frozen variables are never eliminated by *dpll.Simp.
+type freezer interface {
+	SetFrozen(v dpll.Var, frozen bool)
+}
+
+// Solver implements IPASIR using a Backend.
+type Solver struct {
+	s      Backend
+	clause []dpll.Lit
+	assump []dpll.Lit
+	status int
+	model  []dpll.LBool
+	failed map[dpll.Lit]bool
+
+	learn    func(clause []int)
+	learnMax int
+}
+
+var _ IPASIR = (*Solver)(nil)
+
+// New returns an IPASIR solver using s.  If s is a *dpll.Simp all variables
+// are frozen because any variable may appear in clauses added later.  Learnt
+// clauses are only reported to SetLearn callbacks if s calls ExportLearnt
+// from its dpll.Opt.ExportLearnt hook.  NewDPLL and NewSimp install the hook.
+func New(s Backend) *Solver {
+	return &Solver{s: s}
+}
+
+// NewDPLL returns an IPASIR solver using a *dpll.DPLL created with opt.
+func NewDPLL(opt *dpll.Opt) *Solver {
+	s := &Solver{}
+	s.s = dpll.New(s.exportOpt(opt))
+	return s
+}
+
+// NewSimp returns an IPASIR solver using a *dpll.Simp created with opt and
+// simpOpt.
+func NewSimp(opt *dpll.Opt, simpOpt *dpll.SimpOpt) *Solver {
+	s := &Solver{}
+	s.s = dpll.NewSimp(s.exportOpt(opt), simpOpt)
+	return s
+}
+
+// exportOpt returns a copy of opt with its ExportLearnt hook calling
+// s.ExportLearnt.
+func (s *Solver) exportOpt(opt *dpll.Opt) *dpll.Opt {
+	o := &dpll.Opt{}
+	if opt != nil {
+		*o = *opt
+	}
+	o.ExportLearnt = s.ExportLearnt
+	return o
+}
+
+// Backend returns the underlying solver.
+func (s *Solver) Backend() Backend {
+	return s.s
+}
+
+// Signature implements IPASIR.
+func (s *Solver) Signature() string {
+	return signature
+}
+
+// Add implements IPASIR.
+func (s *Solver) Add(lit int) {
+	s.reset()
+	if lit == 0 {
+		s.s.AddClause(s.clause...)
+		s.clause = s.clause[:0]
+		return
+	}
+	s.clause = append(s.clause, s.literal(lit))
+}
+
+// Assume implements IPASIR.
+func (s *Solver) Assume(lit int) {
+	s.reset()
+	s.assump = append(s.assump, s.literal(lit))
+}
+
+// Solve implements IPASIR.
+func (s *Solver) Solve() int {
+	s.reset()
+
+	status := s.s.SolveLimited(s.assump...)
+	s.s.ClearInterrupt()
+
+	s.assump = s.assump[:0]
+	switch {
+	case status.IsTrue():
+		s.status = Satisfiable
+		s.model = s.s.Model()
+	case status.IsFalse():
+		s.status = Unsatisfiable
+		s.failed = make(map[dpll.Lit]bool)
+		for _, p := range s.s.Conflict() {
+			// the conflict contains negated assumptions
+			s.failed[p.Inverse()] = true
+		}
+	default:
+		s.status = Unknown
+	}
+	return s.status
+}
+
+// Val implements IPASIR.
+func (s *Solver) Val(lit int) int {
+	if s.status != Satisfiable {
+		panic("no model")
+	}
+	p := dpll.LiteralInt(lit)
+	if int(p.Var()) >= len(s.model) {
+		return 0
+	}
+	switch val := s.model[p.Var()]; {
+	case val.IsUndef():
+		return 0
+	case val.Xor(p.IsNeg()).IsTrue():
+		return lit
+	default:
+		return -lit
+	}
+}
+
+// Failed implements IPASIR.
+func (s *Solver) Failed(lit int) bool {
+	if s.status != Unsatisfiable {
+		panic("no conflict")
+	}
+	return s.failed[dpll.LiteralInt(lit)]
+}
+
+// SetTerminate implements IPASIR.
+func (s *Solver) SetTerminate(fn func() bool) {
+	s.s.SetTerminate(fn)
+}
+
+// SetLearn implements IPASIR.
+func (s *Solver) SetLearn(maxLen int, fn func(clause []int)) {
+	s.learn = fn
+	s.learnMax = maxLen
+}
+
+// ExportLearnt passes a learnt clause to the callback given to SetLearn.  It
+// may be used as the dpll.Opt.ExportLearnt hook of a Backend passed to New.
+func (s *Solver) ExportLearnt(ps []dpll.Lit, lbd int) {
+	if s.learn == nil || len(ps) > s.learnMax {
+		return
+	}
+	clause := make([]int, len(ps))
+	for i, p := range ps {
+		clause[i] = int(p.Var())
+		if p.IsNeg() {
+			clause[i] = -clause[i]
+		}
+	}
+	s.learn(clause)
+}
+
+// reset discards the results of the last call to Solve.
+func (s *Solver) reset() {
+	s.status = Unknown
+	s.model = nil
+	s.failed = nil
+}
+
+// literal converts lit to a dpll.Lit, creating variables as necessary.
+func (s *Solver) literal(lit int) dpll.Lit {
+	p := dpll.LiteralInt(lit)
+	for s.s.NumVar() < int(p.Var()) {
+		v := s.s.NewVar(dpll.LUndef, true)
+		if f, ok := s.s.(freezer); ok {
+			f.SetFrozen(v, true)
+		}
+	}
+	return p
+}
@@ -0,0 +1,163 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package ipasir
+
+import (
+	"testing"
+
+	"github.com/bmatsuo/dpll"
+)
+
+func addClauses(s IPASIR, clauses ...[]int) {
+	for _, c := range clauses {
+		for _, lit := range c {
+			s.Add(lit)
+		}
+		s.Add(0)
+	}
+}
+
+func testSolver(t *testing.T, s IPASIR) {
+	addClauses(s, []int{1, 2}, []int{-1, 3})
+
+	s.Assume(-2)
+	if r := s.Solve(); r != Satisfiable {
+		t.Fatalf("solve: %d", r)
+	}
+	for _, lit := range []int{1, -2, 3} {
+		if v := s.Val(lit); v != lit {
+			t.Errorf("val %d: %d", lit, v)
+		}
+	}
+
+	s.Assume(-3)
+	s.Assume(-2)
+	s.Assume(4)
+	if r := s.Solve(); r != Unsatisfiable {
+		t.Fatalf("solve: %d", r)
+	}
+	for _, lit := range []int{-3, -2} {
+		if !s.Failed(lit) {
+			t.Errorf("assumption %d not failed", lit)
+		}
+	}
+	if s.Failed(4) {
+		t.Errorf("assumption %d failed", 4)
+	}
+
+	// assumptions are cleared by Solve
+	if r := s.Solve(); r != Satisfiable {
+		t.Fatalf("solve: %d", r)
+	}
+
+	addClauses(s, []int{-3})
+	if r := s.Solve(); r != Satisfiable {
+		t.Fatalf("solve: %d", r)
+	}
+	if v := s.Val(2); v != 2 {
+		t.Errorf("val %d: %d", 2, v)
+	}
+	addClauses(s, []int{-2})
+	if r := s.Solve(); r != Unsatisfiable {
+		t.Fatalf("solve: %d", r)
+	}
+}
+
+func TestSolver_DPLL(t *testing.T) {
+	testSolver(t, New(dpll.New(nil)))
+}
+
+func TestSolver_Simp(t *testing.T) {
+	testSolver(t, New(dpll.NewSimp(nil, nil)))
+}
+
+// addPigeonhole adds clauses placing n+1 pigeons in n holes.
+func addPigeonhole(s IPASIR, n int) (pigeon func(i, j int) int) {
+	pigeon = func(i, j int) int { return i*n + j + 1 }
+	for i := 0; i <= n; i++ {
+		for j := 0; j < n; j++ {
+			s.Add(pigeon(i, j))
+		}
+		s.Add(0)
+	}
+	for j := 0; j < n; j++ {
+		for i := 0; i <= n; i++ {
+			for k := i + 1; k <= n; k++ {
+				addClauses(s, []int{-pigeon(i, j), -pigeon(k, j)})
+			}
+		}
+	}
+	return pigeon
+}
+
+func TestSolver_SetTerminate(t *testing.T) {
+	s := New(dpll.New(nil))
+	pigeon := addPigeonhole(s, 10)
+
+	var calls int
+	s.SetTerminate(func() bool {
+		calls++
+		return true
+	})
+	if r := s.Solve(); r != Unknown {
+		t.Errorf("solve: %d", r)
+	}
+	if calls != 1 {
+		t.Errorf("terminate calls: %d", calls)
+	}
+
+	// the callback is polled by the search itself, so the number of calls is
+	// exact and none are made after Solve returns
+	for _, s := range []*Solver{NewDPLL(nil), NewSimp(nil, nil)} {
+		addPigeonhole(s, 10)
+		calls = 0
+		s.SetTerminate(func() bool {
+			calls++
+			return calls == 100
+		})
+		if r := s.Solve(); r != Unknown {
+			t.Errorf("solve: %d", r)
+		}
+		if calls != 100 {
+			t.Errorf("terminate calls: %d", calls)
+		}
+	}
+
+	// the interrupt does not carry over to the next call
+	s.SetTerminate(nil)
+	s.Assume(-pigeon(0, 0))
+	s.Add(pigeon(0, 0))
+	s.Add(0)
+	if r := s.Solve(); r != Unsatisfiable {
+		t.Errorf("solve: %d", r)
+	}
+}
+
+func TestSolver_SetLearn(t *testing.T) {
+	for _, s := range []*Solver{NewDPLL(nil), NewSimp(nil, nil)} {
+		addPigeonhole(s, 5)
+
+		const maxLen = 3
+		var n int
+		s.SetLearn(maxLen, func(clause []int) {
+			n++
+			if len(clause) > maxLen {
+				t.Errorf("clause length %d (> %d)", len(clause), maxLen)
+			}
+			for _, lit := range clause {
+				if lit == 0 || lit > 30 || lit < -30 {
+					t.Errorf("literal %d", lit)
+				}
+			}
+		})
+		if r := s.Solve(); r != Unsatisfiable {
+			t.Errorf("solve: %d", r)
+		}
+		if n == 0 {
+			t.Errorf("no clauses learnt")
+		}
+	}
+}
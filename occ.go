@@ -148,3 +148,29 @@ func (o *clauseOccLists) Smudge(p Var) {
 		o.dirties = append(o.dirties, p)
 	}
 }
+
+// clone returns a copy of o which refers to the clauses returned by clone.
+func (o *clauseOccLists) clone(clone func(*Clause) *Clause) *clauseOccLists {
+	o2 := &clauseOccLists{
+		_occs:   make([][]*Clause, len(o._occs)),
+		_dirty:  append([]bool(nil), o._dirty...),
+		dirties: append([]Var(nil), o.dirties...),
+	}
+	for i, cs := range o._occs {
+		if cs == nil {
+			continue
+		}
+		cs2 := make([]*Clause, len(cs))
+		for j, c := range cs {
+			cs2[j] = clone(c)
+		}
+		o2._occs[i] = cs2
+	}
+	if o.occs != nil {
+		o2.occs = o2._occs[:len(o.occs)]
+	}
+	if o.dirty != nil {
+		o2.dirty = o2._dirty[:len(o.dirty)]
+	}
+	return o2
+}
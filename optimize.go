@@ -0,0 +1,289 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import "sort"
+
+// WeightedLit is a term of an Objective.  The term costs Weight when Lit is
+// true.
+type WeightedLit struct {
+	Lit    Lit
+	Weight int64
+}
+
+// Objective is a sum of weighted literals to be minimized.  Weights may not
+// be negative.
+type Objective []WeightedLit
+
+// Cost returns the value of obj in model.
+func (obj Objective) Cost(model []LBool) int64 {
+	var cost int64
+	for _, t := range obj {
+		if model[t.Lit.Var()].Xor(t.Lit.IsNeg()).IsTrue() {
+			cost += t.Weight
+		}
+	}
+	return cost
+}
+
+// OptimizeStrategy determines how Optimize searches for better models.
+type OptimizeStrategy int
+
+// Available OptimizeStrategy values.
+const (
+	// LinearSearch requires each model to improve on the last until the
+	// problem becomes unsatisfiable.
+	LinearSearch OptimizeStrategy = iota
+
+	// BinarySearch bisects the interval between the cost of the best model
+	// and a proven lower bound.
+	BinarySearch
+)
+
+// OptimizeOpt holds options for Optimize.
+type OptimizeOpt struct {
+	Strategy OptimizeStrategy
+
+	// Improve is called with each model found which is better than the
+	// previous one, along with the cost of each objective in the model.
+	Improve func(model []LBool, costs []int64)
+}
+
+// optSolver is the part of DPLL and Simp used by Optimize.
+type optSolver interface {
+	SolveLimited(assump ...Lit) LBool
+	Model() []LBool
+	NewVar(upol LBool, dvar bool) Var
+	AddClause(ps ...Lit) bool
+}
+
+// Optimize finds a model minimizing objs in lexicographic order.  Each
+// objective is minimized in turn with the costs of earlier objectives held
+// at their minimum.  Bounds on the cost are imposed by assumptions on the
+// outputs of a generalized totalizer encoding of the objective, which is
+// added to the problem.  The encoding does not restrict the models of the
+// problem, so the solver may be used normally after Optimize returns.
+//
+// The status returned is LTrue if model is optimal and LFalse if the problem
+// is unsatisfiable.  If a budget is exhausted or the search is interrupted
+// the status is LUndef and the best model found, if any, is returned.
+func (d *DPLL) Optimize(objs []Objective, opt *OptimizeOpt) (model []LBool, costs []int64, status LBool) {
+	o := &optimizer{s: d}
+	return o.optimize(objs, opt)
+}
+
+// Optimize behaves like DPLL.Optimize.  The variables of objs may not be
+// eliminated.  They and the outputs of the encodings are frozen until
+// Optimize returns.
+func (s *Simp) Optimize(objs []Objective, opt *OptimizeOpt) (model []LBool, costs []int64, status LBool) {
+	var extraFrozen []Var
+	freeze := func(v Var) {
+		if s.IsEliminated(v) {
+			panic("objective contains an eliminated variable")
+		}
+		if !s.frozen[v] {
+			s.SetFrozen(v, true)
+			extraFrozen = append(extraFrozen, v)
+		}
+	}
+	defer func() {
+		for _, v := range extraFrozen {
+			s.SetFrozen(v, false)
+		}
+	}()
+	for _, obj := range objs {
+		for _, t := range obj {
+			freeze(t.Lit.Var())
+		}
+	}
+	o := &optimizer{s: s, freeze: freeze}
+	return o.optimize(objs, opt)
+}
+
+// optimizer holds the state of a call to Optimize.
+type optimizer struct {
+	s      optSolver
+	freeze func(v Var) // protects assumed variables from elimination
+	opt    *OptimizeOpt
+	objs   []Objective
+	assump []Lit // bounds on the objectives already minimized
+	costs  []int64
+	model  []LBool
+}
+
+func (o *optimizer) optimize(objs []Objective, opt *OptimizeOpt) (model []LBool, costs []int64, status LBool) {
+	for _, obj := range objs {
+		for _, t := range obj {
+			if t.Weight < 0 {
+				panic("negative objective weight")
+			}
+		}
+	}
+	o.opt = opt
+	if o.opt == nil {
+		o.opt = &OptimizeOpt{}
+	}
+	o.objs = objs
+
+	status = o.s.SolveLimited()
+	if !status.IsTrue() {
+		return nil, nil, status
+	}
+	o.improve(o.s.Model())
+
+	for i := range objs {
+		if status = o.minimize(i); !status.IsTrue() {
+			return o.model, o.costs, LUndef
+		}
+	}
+	return o.model, o.costs, LTrue
+}
+
+// improve records model as the best found.
+func (o *optimizer) improve(model []LBool) {
+	o.model = model
+	o.costs = make([]int64, len(o.objs))
+	for i, obj := range o.objs {
+		o.costs[i] = obj.Cost(model)
+	}
+	if o.opt.Improve != nil {
+		o.opt.Improve(o.model, o.costs)
+	}
+}
+
+// minimize minimizes objective i, whose cost in the best model is an upper
+// bound, and then bounds it by its minimum in subsequent searches.  minimize
+// returns LUndef if the search could not be completed.
+func (o *optimizer) minimize(i int) LBool {
+	if o.costs[i] == 0 && i == len(o.objs)-1 {
+		return LTrue
+	}
+	// sums above the upper bound need not be distinguished
+	tot := o.totalizer(o.objs[i], o.costs[i]+1)
+
+	lo := int64(0)
+	for lo < o.costs[i] {
+		bound := o.costs[i] - 1
+		if o.opt.Strategy == BinarySearch {
+			bound = lo + (o.costs[i]-lo)/2
+		}
+		assump := append(o.assump, tot.atMost(bound)...)
+		switch status := o.s.SolveLimited(assump...); {
+		case status.IsTrue():
+			o.improve(o.s.Model())
+		case status.IsFalse():
+			lo = bound + 1
+		default:
+			return LUndef
+		}
+	}
+	o.assump = append(o.assump, tot.atMost(o.costs[i])...)
+	return LTrue
+}
+
+// totalizer is the root of a generalized totalizer.  The output lits[i] is
+// true whenever the sum of the weights of true input literals is at least
+// vals[i].  Sums of at least the cap given when the totalizer was built
+// are represented by the largest value.
+type totalizer struct {
+	vals []int64
+	lits []Lit
+}
+
+// atMost returns the assumptions which bound the sum by k.
+func (t *totalizer) atMost(k int64) []Lit {
+	i := sort.Search(len(t.vals), func(i int) bool { return t.vals[i] > k })
+	if i == len(t.vals) {
+		return nil
+	}
+	return []Lit{t.lits[i].Inverse()}
+}
+
+// totalizer encodes obj as a generalized totalizer with sums capped at
+// limit.  The outputs of the root are linked so that each implies the
+// outputs of smaller values, allowing a bound to be imposed with a single
+// assumption.
+func (o *optimizer) totalizer(obj Objective, limit int64) *totalizer {
+	var nodes []*totalizer
+	for _, t := range obj {
+		if t.Weight == 0 {
+			continue
+		}
+		w := t.Weight
+		if w > limit {
+			w = limit
+		}
+		nodes = append(nodes, &totalizer{vals: []int64{w}, lits: []Lit{t.Lit}})
+	}
+	for len(nodes) > 1 {
+		var next []*totalizer
+		for i := 0; i+1 < len(nodes); i += 2 {
+			next = append(next, o.merge(nodes[i], nodes[i+1], limit))
+		}
+		if len(nodes)%2 == 1 {
+			next = append(next, nodes[len(nodes)-1])
+		}
+		nodes = next
+	}
+	if len(nodes) == 0 {
+		return &totalizer{}
+	}
+
+	root := nodes[0]
+	for i, p := range root.lits {
+		if o.freeze != nil {
+			o.freeze(p.Var())
+		}
+		if i > 0 {
+			o.s.AddClause(p.Inverse(), root.lits[i-1])
+		}
+	}
+	return root
+}
+
+// merge returns a node whose outputs encode the sums of the outputs of a and
+// b.
+func (o *optimizer) merge(a, b *totalizer, limit int64) *totalizer {
+	out := make(map[int64]Lit)
+	output := func(v int64) Lit {
+		if v > limit {
+			v = limit
+		}
+		p, ok := out[v]
+		if !ok {
+			p = Literal(o.s.NewVar(LUndef, true), false)
+			out[v] = p
+		}
+		return p
+	}
+	for i, p := range a.lits {
+		o.s.AddClause(p.Inverse(), output(a.vals[i]))
+	}
+	for j, q := range b.lits {
+		o.s.AddClause(q.Inverse(), output(b.vals[j]))
+	}
+	for i, p := range a.lits {
+		for j, q := range b.lits {
+			o.s.AddClause(p.Inverse(), q.Inverse(), output(a.vals[i]+b.vals[j]))
+		}
+	}
+
+	node := &totalizer{}
+	for v := range out {
+		node.vals = append(node.vals, v)
+	}
+	sort.Sort(int64Slice(node.vals))
+	for _, v := range node.vals {
+		node.lits = append(node.lits, out[v])
+	}
+	return node
+}
+
+type int64Slice []int64
+
+func (s int64Slice) Len() int           { return len(s) }
+func (s int64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s int64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,147 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func randomObjective(r *rand.Rand, nvar, nterm int) Objective {
+	var obj Objective
+	for i := 0; i < nterm; i++ {
+		p := Literal(Var(r.Intn(nvar)+1), r.Intn(2) == 0)
+		obj = append(obj, WeightedLit{p, int64(r.Intn(5) + 1)})
+	}
+	return obj
+}
+
+// bruteOptimize returns the lexicographically minimal costs of objs over the
+// models of p, or nil if p is unsatisfiable.
+func bruteOptimize(p *dimacs.Problem, objs []Objective) []int64 {
+	var best []int64
+	model := make([]LBool, p.NumVar+1)
+	for x := 0; x < 1<<uint(p.NumVar); x++ {
+		for v := 1; v <= p.NumVar; v++ {
+			model[v] = LiftBool(x&(1<<uint(v-1)) != 0)
+		}
+		if !satisfies(p, model) {
+			continue
+		}
+		costs := make([]int64, len(objs))
+		for i, obj := range objs {
+			costs[i] = obj.Cost(model)
+		}
+		if best == nil || lexLess(costs, best) {
+			best = costs
+		}
+	}
+	return best
+}
+
+func satisfies(p *dimacs.Problem, model []LBool) bool {
+	for _, c := range p.Clauses {
+		sat := false
+		for _, x := range c {
+			if model[x.Var()].Xor(x.Neg()).IsTrue() {
+				sat = true
+				break
+			}
+		}
+		if !sat {
+			return false
+		}
+	}
+	return true
+}
+
+func lexLess(a, b []int64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+type optimizeFunc func(objs []Objective, opt *OptimizeOpt) ([]LBool, []int64, LBool)
+
+func TestDPLL_Optimize(t *testing.T) {
+	for seed := int64(1); seed < 30; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		p := randomProblem(seed, 12, 30)
+		objs := []Objective{randomObjective(r, p.NumVar, 6), randomObjective(r, p.NumVar, 8)}
+		want := bruteOptimize(p, objs)
+
+		for _, strategy := range []OptimizeStrategy{LinearSearch, BinarySearch} {
+			d := New(nil)
+			addProblem(d, p)
+			s := NewSimp(nil, nil)
+			addProblem(s, p)
+			fns := map[string]optimizeFunc{"dpll": d.Optimize, "simp": s.Optimize}
+			for name, fn := range fns {
+				var last []int64
+				opt := &OptimizeOpt{
+					Strategy: strategy,
+					Improve: func(model []LBool, costs []int64) {
+						if last != nil && !lexLess(costs, last) {
+							t.Errorf("seed %d %s: costs %v do not improve on %v", seed, name, costs, last)
+						}
+						last = costs
+					},
+				}
+				model, costs, status := fn(objs, opt)
+				if want == nil {
+					if !status.IsFalse() {
+						t.Errorf("seed %d %s: status %v", seed, name, status)
+					}
+					continue
+				}
+				if !status.IsTrue() {
+					t.Errorf("seed %d %s: status %v", seed, name, status)
+					continue
+				}
+				if !satisfies(p, model) {
+					t.Errorf("seed %d %s: invalid model", seed, name)
+				}
+				if !costsEqual(costs, want) || !costsEqual(last, want) {
+					t.Errorf("seed %d %s: costs %v (expected %v)", seed, name, costs, want)
+				}
+			}
+
+			// the encoding does not restrict later searches
+			if want != nil && !d.Solve() {
+				t.Errorf("seed %d: unsatisfiable after Optimize", seed)
+			}
+		}
+	}
+}
+
+func costsEqual(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDPLL_Optimize_budget(t *testing.T) {
+	p := randomProblem(3, 200, 820)
+	r := rand.New(rand.NewSource(3))
+	d := New(nil)
+	addProblem(d, p)
+	d.SetConflictBudget(10)
+	_, _, status := d.Optimize([]Objective{randomObjective(r, p.NumVar, 100)}, nil)
+	if !status.IsUndef() {
+		t.Errorf("status %v", status)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import "log"
+
+// probe performs a round of failed literal probing on the roots of the binary
+// implication graph.  Failed literals and literals implied by both polarities
+// of a probed variable are learnt as units.  Literals implied through longer
+// clauses are learnt as hyper-binary resolvents.  The round ends when
+// ProbeBudget propagations have been made.  probe returns false if the clause
+// set was found to be unsatisfiable.
+func (d *DPLL) probe() bool {
+	if d.decisionLevel() != 0 {
+		panic("non-root decision level")
+	}
+	if !d.ok || d.propagate() != nil {
+		d.ok = false
+		return false
+	}
+
+	roots := d.probeRoots()
+	if len(roots) == 0 {
+		return true
+	}
+
+	nfailed := d.nprobeFailed
+	nimplied := d.nprobeImplied
+	nhbr := d.nprobeHBR
+
+	limit := d.npropogations + uint64(d.ProbeBudget)
+	start := d.probeHead % len(roots)
+	for i := 0; i < len(roots) && d.npropogations < limit && d.withinBudget(); i++ {
+		p := roots[(start+i)%len(roots)]
+		d.probeHead++
+		if !d.ValueLit(p).IsUndef() {
+			continue
+		}
+		if !d.probeVar(p) {
+			d.ok = false
+			return false
+		}
+	}
+
+	if d.Verbosity >= 2 {
+		log.Printf("|  Probing:    %8d roots %8d failed %8d implied %8d hbr         |",
+			len(roots), d.nprobeFailed-nfailed, d.nprobeImplied-nimplied, d.nprobeHBR-nhbr)
+	}
+
+	return true
+}
+
+// probeRoots returns the literals to probe.  Roots of the binary implication
+// graph, literals which imply others but are not implied by any literal, are
+// returned first.  The negation of a root implies nothing so variables which
+// have implications for both polarities follow, as these are the only
+// variables for which probing can find implied literals common to both
+// polarities.  At most one literal is returned for each variable.
+func (d *DPLL) probeRoots() []Lit {
+	n := 2 * (d.NumVar() + 1)
+	out := make([]bool, n)
+	in := make([]bool, n)
+	mark := func(cs []*Clause) {
+		for _, c := range cs {
+			if c.Len() != 2 || isRemoved(c) || d.satisfied(c) {
+				continue
+			}
+			// the clause (p1 | p2) represents the implications ~p1 -> p2 and
+			// ~p2 -> p1.
+			out[c.Lit[0].Inverse()] = true
+			out[c.Lit[1].Inverse()] = true
+			in[c.Lit[0]] = true
+			in[c.Lit[1]] = true
+		}
+	}
+	mark(d.clauses)
+	mark(d.learnt)
+
+	var roots, both []Lit
+	for v := Var(1); int(v) <= d.NumVar(); v++ {
+		if !d.decision[v] || !d.Value(v).IsUndef() {
+			continue
+		}
+		pos, neg := Literal(v, false), Literal(v, true)
+		switch {
+		case out[pos] && !in[pos]:
+			roots = append(roots, pos)
+		case out[neg] && !in[neg]:
+			roots = append(roots, neg)
+		case out[pos] && out[neg]:
+			both = append(both, pos)
+		}
+	}
+	return append(roots, both...)
+}
+
+// probeVar probes both polarities of the variable in p.  probeVar returns
+// false if the clause set was found to be unsatisfiable.
+func (d *DPLL) probeVar(p Lit) bool {
+	pos, ok := d.probeLit(p)
+	if !ok {
+		return d.probeFailed(p)
+	}
+	neg, ok := d.probeLit(p.Inverse())
+	if !ok {
+		return d.probeFailed(p.Inverse())
+	}
+
+	// literals implied by both p and ~p are implied at the root level
+	implied := newLitSet()
+	for _, q := range pos {
+		implied.insert(q)
+	}
+	for _, q := range neg {
+		if _, ok := implied[q]; ok && d.ValueLit(q).IsUndef() {
+			d.nprobeImplied++
+			d.uncheckedEnqueue(q, nil)
+		}
+	}
+	return d.propagate() == nil
+}
+
+// probeFailed learns the failed literal p as the unit ~p.
+func (d *DPLL) probeFailed(p Lit) bool {
+	d.nprobeFailed++
+	if !d.enqueue(p.Inverse(), nil) {
+		return false
+	}
+	return d.propagate() == nil
+}
+
+// probeLit uses assume to propagate p at a new decision level and returns the
+// literals implied by p.  Implied literals which are not reachable from p in
+// the binary implication graph are learnt as hyper-binary resolvents (~p |
+// q), skipping any resolvent already implied by those added before it.  If p
+// is a failed literal probeLit returns false.
+func (d *DPLL) probeLit(p Lit) (implied []Lit, ok bool) {
+	reached := newLitSet()
+	d.markBinaryImplied(p, reached)
+
+	before, ok := d.assume([]Lit{p})
+	if !ok {
+		d.cancelUntil(0)
+		return nil, false
+	}
+
+	var hbr []Lit
+	implied = append(implied, d.trail[before:]...)
+	for _, q := range implied {
+		if _, ok := reached[q]; ok {
+			continue
+		}
+		if c := d.reason(q.Var()); c != nil && c.Len() > 2 {
+			hbr = append(hbr, q)
+			d.markBinaryImplied(q, reached)
+		}
+	}
+	d.cancelUntil(0)
+
+	for _, q := range hbr {
+		c := d.newClause([]Lit{p.Inverse(), q}, true)
+		c.LBD = 2 // the literals are assigned at different levels
+		d.learnt = append(d.learnt, c)
+		d.attachClause(c)
+		d.nprobeHBR++
+	}
+
+	return implied, true
+}
+
+// markBinaryImplied inserts into reached all literals which are transitively
+// implied by p through binary clauses.
+func (d *DPLL) markBinaryImplied(p Lit, reached litSet) {
+	reached.insert(p)
+	stack := []Lit{p}
+	for len(stack) > 0 {
+		q := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		// watchers of q are clauses containing ~q
+		for _, w := range d.watches.Lookup(q) {
+			c := w.c
+			if c.Len() != 2 || isRemoved(c) {
+				continue
+			}
+			r := c.Lit[0]
+			if r == q.Inverse() {
+				r = c.Lit[1]
+			}
+			if _, ok := reached[r]; !ok {
+				reached.insert(r)
+				stack = append(stack, r)
+			}
+		}
+	}
+}
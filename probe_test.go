@@ -0,0 +1,105 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import "testing"
+
+func TestDPLL_probe(t *testing.T) {
+	d := New(&Opt{Probe: true})
+	for i := 0; i < 3; i++ {
+		d.NewVar(LUndef, true)
+	}
+	a, b, c := LiteralInt(1), LiteralInt(2), LiteralInt(3)
+
+	// a is a failed literal
+	d.AddClause(a.Inverse(), b)
+	d.AddClause(a.Inverse(), c)
+	d.AddClause(b.Inverse(), c.Inverse())
+
+	if !d.probe() {
+		t.Fatalf("unsatisfiable")
+	}
+	if !d.ValueLit(a).IsFalse() {
+		t.Errorf("failed literal %v: %v", a, d.ValueLit(a))
+	}
+	if d.nprobeFailed == 0 {
+		t.Errorf("no failed literals")
+	}
+}
+
+func TestDPLL_probeVar(t *testing.T) {
+	d := New(&Opt{Probe: true})
+	for i := 0; i < 4; i++ {
+		d.NewVar(LUndef, true)
+	}
+	e, f, g, h := LiteralInt(1), LiteralInt(2), LiteralInt(3), LiteralInt(4)
+
+	// e is implied by both polarities of f
+	d.AddClause(f.Inverse(), e)
+	d.AddClause(f, g)
+	d.AddClause(g.Inverse(), h.Inverse(), e)
+	d.AddClause(g.Inverse(), h)
+
+	if !d.probeVar(f) {
+		t.Fatalf("unsatisfiable")
+	}
+	if !d.ValueLit(e).IsTrue() {
+		t.Errorf("implied literal %v: %v", e, d.ValueLit(e))
+	}
+	if d.nprobeImplied == 0 {
+		t.Errorf("no implied literals")
+	}
+}
+
+func TestDPLL_probe_hbr(t *testing.T) {
+	d := New(&Opt{Probe: true})
+	for i := 0; i < 4; i++ {
+		d.NewVar(LUndef, true)
+	}
+	a, b, c, e := LiteralInt(1), LiteralInt(2), LiteralInt(3), LiteralInt(4)
+
+	d.AddClause(a.Inverse(), b)
+	d.AddClause(a.Inverse(), c)
+	d.AddClause(b.Inverse(), c.Inverse(), e)
+
+	if !d.probe() {
+		t.Fatalf("unsatisfiable")
+	}
+	if d.nprobeHBR != 1 {
+		t.Fatalf("hyper-binary resolvents: %d (!= 1)", d.nprobeHBR)
+	}
+	c2 := d.learnt[len(d.learnt)-1]
+	if c2.Len() != 2 || c2.Lit[0] != a.Inverse() || c2.Lit[1] != e {
+		t.Errorf("resolvent: %v", c2.Lit)
+	}
+	if c2.LBD != 2 {
+		t.Errorf("resolvent LBD: %d (!= 2)", c2.LBD)
+	}
+}
+
+func TestDPLL_Solve_probe(t *testing.T) {
+	tests := []struct {
+		path string
+		sat  bool
+	}{
+		{"testdata/factoring_2_3.cnf", true},
+		{"testdata/factoring_2_3_UNSAT.cnf", false},
+		{"testdata/factoring_3_5.cnf", true},
+		{"testdata/factoring_3_5_UNSAT.cnf", false},
+		{"testdata/factoring_5_7.cnf", true},
+	}
+	for _, test := range tests {
+		d := New(&Opt{Probe: true, RestartFirst: 10})
+		_, err := DecodeFile(d, test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sat := d.Solve()
+		if sat != test.sat {
+			t.Errorf("%s: sat %v (!= %v)", test.path, sat, test.sat)
+		}
+	}
+}
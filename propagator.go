@@ -0,0 +1,322 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Propagator is an external theory connected to the search.  The propagator
+// is notified of assignments to observed variables and may propagate
+// literals, add clauses, and reject models.  All literals given to the
+// solver by a Propagator must be of observed variables.
+type Propagator interface {
+	// Assign is called when an observed variable is assigned, making p true.
+	Assign(p Lit)
+
+	// NewDecisionLevel is called when the solver opens a decision level.
+	NewDecisionLevel()
+
+	// Backtrack is called when assignments above decision level are undone.
+	Backtrack(level int)
+
+	// Propagate returns literals implied by the current assignment.
+	// Propagate is called until it returns no unassigned literals.
+	Propagate() []Lit
+
+	// Reason returns a clause explaining a literal p returned by Propagate.
+	// The clause contains p and literals which were false when p was
+	// propagated.  Reason is only called when the solver needs the clause.
+	Reason(p Lit) []Lit
+
+	// HasExternalClause returns true if ExternalClause may be called.
+	HasExternalClause() bool
+
+	// ExternalClause returns a clause to add to the problem.  The clause may
+	// be removed like a learnt clause if forgettable is true.
+	ExternalClause() (ps []Lit, forgettable bool)
+
+	// CheckModel is called with a complete assignment before the solver
+	// declares a model.  To reject the model CheckModel must return false
+	// and have an external clause which is falsified by model.  The model
+	// must not be retained.
+	CheckModel(model []LBool) bool
+}
+
+// lazyReason is the reason for literals propagated by a Propagator.  The
+// reason clause is requested when it is needed.
+var lazyReason = &Clause{}
+
+// emptyClause signals a conflict at the root level from an external clause.
+var emptyClause = &Clause{}
+
+// ConnectPropagator connects p to the search.  A nil p disconnects the
+// current propagator.  The propagator is notified of assignments to
+// variables given to ObserveVar.
+func (d *DPLL) ConnectPropagator(p Propagator) {
+	if d.decisionLevel() != 0 {
+		panic("non-root decision level")
+	}
+	d.prop = p
+}
+
+// ObserveVar causes the connected Propagator to be notified of assignments to
+// v.  Assignments made at the root level before v is observed are not
+// reported.
+func (d *DPLL) ObserveVar(v Var) {
+	for len(d.observed) <= int(v) {
+		d.observed = append(d.observed, false)
+	}
+	d.observed[v] = true
+}
+
+func (d *DPLL) isObserved(v Var) bool {
+	return int(v) < len(d.observed) && d.observed[v]
+}
+
+func (d *DPLL) checkObserved(p Lit) {
+	if !d.isObserved(p.Var()) {
+		panic(fmt.Sprintf("literal of unobserved variable: %v", p))
+	}
+}
+
+// propagateExternal adds external clauses and enqueues literals from the
+// connected Propagator until neither produces anything new.  Clause
+// propagation is performed in between.  propagateExternal returns a
+// conflicting clause if one is found.
+func (d *DPLL) propagateExternal() *Clause {
+	for {
+		if confl := d.propagate(); confl != nil {
+			return confl
+		}
+
+		added := false
+		for d.prop.HasExternalClause() {
+			added = true
+			d.propRejected = false
+			if confl := d.addExternalClause(d.prop.ExternalClause()); confl != nil {
+				return confl
+			}
+		}
+		if d.propRejected {
+			panic("model rejected without an external clause")
+		}
+		if added {
+			continue
+		}
+
+		n := 0
+		for _, p := range d.prop.Propagate() {
+			d.checkObserved(p)
+			switch val := d.ValueLit(p); {
+			case val.IsTrue():
+			case val.IsFalse():
+				return d.externalConflict(p)
+			case d.decisionLevel() == 0:
+				d.nextPropagated++
+				d.uncheckedEnqueue(p, nil)
+				n++
+			default:
+				d.nextPropagated++
+				d.uncheckedEnqueue(p, lazyReason)
+				n++
+			}
+		}
+		if n == 0 {
+			return nil
+		}
+	}
+}
+
+// externalReason requests the reason clause for the assignment of v and adds
+// it as a learnt clause.
+func (d *DPLL) externalReason(v Var) *Clause {
+	p := Literal(v, d.Value(v).IsFalse())
+	ps := d.externalReasonLits(p)
+	if len(ps) == 1 {
+		if d.level(v) > 0 {
+			panic(fmt.Sprintf("empty reason for %v", p))
+		}
+		d.vardata[v].Reason = nil
+		return nil
+	}
+	c := d.newClause(ps, true)
+	c.LBD = d.computeLBD(ps)
+	d.learnt = append(d.learnt, c)
+	d.attachClause(c)
+	d.vardata[v].Reason = c
+	return c
+}
+
+// externalConflict requests the reason for the propagation of the false
+// literal p and returns it as a conflicting clause.
+func (d *DPLL) externalConflict(p Lit) *Clause {
+	ps := d.externalReasonLits(p)
+	return d.addExternalLits(ps, true)
+}
+
+// externalReasonLits returns the reason for p from the Propagator with p
+// first and the remaining literals in order of decreasing level.
+func (d *DPLL) externalReasonLits(p Lit) []Lit {
+	d.nextReason++
+	reason := d.prop.Reason(p)
+	ps := make([]Lit, 0, len(reason))
+	ps = append(ps, p)
+	for _, q := range reason {
+		d.checkObserved(q)
+		if q == p {
+			continue
+		}
+		if !d.ValueLit(q).IsFalse() {
+			panic(fmt.Sprintf("reason for %v contains non-false literal %v", p, q))
+		}
+		ps = append(ps, q)
+	}
+	sort.Sort(&litsByLevel{d, ps[1:]})
+	return ps
+}
+
+// addExternalClause adds a clause from the Propagator and returns a
+// conflicting clause if it is falsified by the current assignment.
+func (d *DPLL) addExternalClause(ps []Lit, forgettable bool) *Clause {
+	d.nextClause++
+
+	c := make([]Lit, len(ps))
+	copy(c, ps)
+	sort.Sort(litSlice(c))
+	var j int
+	for i, p := 0, LitUndef; i < len(c); i++ {
+		d.checkObserved(c[i])
+		if c[i] == p.Inverse() {
+			return nil
+		}
+		rootFalse := d.ValueLit(c[i]).IsFalse() && d.level(c[i].Var()) == 0
+		if c[i] != p && !rootFalse {
+			p = c[i]
+			c[j] = p
+			j++
+		}
+	}
+	c = c[:j]
+
+	return d.addExternalLits(c, forgettable)
+}
+
+// addExternalLits adds the clause ps, which has no duplicate literals.  If ps
+// is unit or falsified under the current assignment the search backtracks to
+// the level at which it became so and the clause is either used to propagate
+// or returned as a conflict.
+func (d *DPLL) addExternalLits(ps []Lit, learnt bool) *Clause {
+	sort.Sort(&litsByValue{d, ps})
+
+	nfree := 0
+	for _, p := range ps {
+		if !d.ValueLit(p).IsFalse() {
+			nfree++
+		}
+	}
+
+	switch {
+	case len(ps) == 0:
+		d.cancelUntil(0)
+		return emptyClause
+	case d.ValueLit(ps[0]).IsTrue() && d.level(ps[0].Var()) == 0:
+		return nil
+	case len(ps) == 1:
+		d.cancelUntil(0)
+		if d.ValueLit(ps[0]).IsFalse() {
+			return emptyClause
+		}
+		if d.ValueLit(ps[0]).IsUndef() {
+			d.uncheckedEnqueue(ps[0], nil)
+		}
+		return nil
+	}
+
+	c := d.newClause(ps, learnt)
+	if learnt {
+		c.LBD = d.computeLBD(ps)
+		d.learnt = append(d.learnt, c)
+	} else {
+		d.clauses = append(d.clauses, c)
+	}
+	d.attachClause(c)
+
+	switch {
+	case nfree == 0:
+		d.cancelUntil(d.level(ps[0].Var()))
+		return c
+	case nfree == 1 && d.ValueLit(ps[0]).IsUndef():
+		d.cancelUntil(d.level(ps[1].Var()))
+		d.uncheckedEnqueue(ps[0], c)
+	case nfree == 1 && d.level(ps[0].Var()) > d.level(ps[1].Var()):
+		// ps[0] is true but would have been propagated at a lower level
+		d.cancelUntil(d.level(ps[1].Var()))
+		d.uncheckedEnqueue(ps[0], c)
+	}
+	return nil
+}
+
+// checkExternalModel asks the Propagator to accept the current assignment.
+func (d *DPLL) checkExternalModel() bool {
+	if d.prop.CheckModel(d.assigns) {
+		return true
+	}
+	d.propRejected = true
+	return false
+}
+
+// litsByLevel sorts false literals in order of decreasing level.
+type litsByLevel struct {
+	d  *DPLL
+	ps []Lit
+}
+
+func (s *litsByLevel) Len() int { return len(s.ps) }
+func (s *litsByLevel) Less(i, j int) bool {
+	return s.d.level(s.ps[i].Var()) > s.d.level(s.ps[j].Var())
+}
+func (s *litsByLevel) Swap(i, j int) { s.ps[i], s.ps[j] = s.ps[j], s.ps[i] }
+
+// litsByValue sorts true literals first, then unassigned literals, then
+// false literals in order of decreasing level.
+type litsByValue struct {
+	d  *DPLL
+	ps []Lit
+}
+
+func (s *litsByValue) rank(p Lit) int {
+	switch val := s.d.ValueLit(p); {
+	case val.IsTrue():
+		return -2
+	case val.IsUndef():
+		return -1
+	default:
+		return s.d.decisionLevel() - s.d.level(p.Var())
+	}
+}
+
+func (s *litsByValue) Len() int { return len(s.ps) }
+func (s *litsByValue) Less(i, j int) bool {
+	return s.rank(s.ps[i]) < s.rank(s.ps[j])
+}
+func (s *litsByValue) Swap(i, j int) { s.ps[i], s.ps[j] = s.ps[j], s.ps[i] }
+
+// ConnectPropagator behaves like DPLL.ConnectPropagator.
+func (s *Simp) ConnectPropagator(p Propagator) {
+	s.d.ConnectPropagator(p)
+}
+
+// ObserveVar behaves like DPLL.ObserveVar.  Observed variables are frozen so
+// that they are never eliminated.
+func (s *Simp) ObserveVar(v Var) {
+	if s.IsEliminated(v) {
+		panic("cannot observe eliminated variable")
+	}
+	s.SetFrozen(v, true)
+	s.d.ObserveVar(v)
+}
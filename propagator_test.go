@@ -0,0 +1,188 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"testing"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// testTheory is a Propagator enforcing that at most one variable in amo is
+// true, by propagation, and that an odd number of variables in xor are true,
+// by rejecting models.
+type testTheory struct {
+	amo   []Var
+	xor   []Var
+	trail []Lit
+	lim   []int
+	cause map[Lit]Lit
+	queue [][]Lit
+
+	nassign    int
+	nbacktrack int
+	nreject    int
+}
+
+func newTestTheory(amo, xor []Var) *testTheory {
+	return &testTheory{amo: amo, xor: xor, cause: make(map[Lit]Lit)}
+}
+
+func (th *testTheory) Assign(p Lit) {
+	th.nassign++
+	th.trail = append(th.trail, p)
+}
+
+func (th *testTheory) NewDecisionLevel() {
+	th.lim = append(th.lim, len(th.trail))
+}
+
+func (th *testTheory) Backtrack(level int) {
+	th.nbacktrack++
+	if level < len(th.lim) {
+		th.trail = th.trail[:th.lim[level]]
+		th.lim = th.lim[:level]
+	}
+}
+
+func (th *testTheory) isTrue(v Var) (Lit, bool) {
+	for _, p := range th.trail {
+		if p == Literal(v, false) {
+			return p, true
+		}
+	}
+	return LitUndef, false
+}
+
+func (th *testTheory) Propagate() []Lit {
+	for _, v := range th.amo {
+		x, ok := th.isTrue(v)
+		if !ok {
+			continue
+		}
+		var ps []Lit
+		for _, w := range th.amo {
+			if w != v {
+				q := Literal(w, true)
+				th.cause[q] = x
+				ps = append(ps, q)
+			}
+		}
+		return ps
+	}
+	return nil
+}
+
+func (th *testTheory) Reason(p Lit) []Lit {
+	return []Lit{p, th.cause[p].Inverse()}
+}
+
+func (th *testTheory) HasExternalClause() bool {
+	return len(th.queue) > 0
+}
+
+func (th *testTheory) ExternalClause() ([]Lit, bool) {
+	ps := th.queue[0]
+	th.queue = th.queue[1:]
+	return ps, false
+}
+
+func (th *testTheory) CheckModel(model []LBool) bool {
+	odd := false
+	var block []Lit
+	for _, v := range th.xor {
+		odd = odd != model[v].IsTrue()
+		block = append(block, Literal(v, model[v].IsTrue()))
+	}
+	if odd {
+		return true
+	}
+	th.nreject++
+	th.queue = append(th.queue, block)
+	return false
+}
+
+// encode returns the clauses of th.
+func (th *testTheory) encode() [][]dimacs.Lit {
+	var cs [][]dimacs.Lit
+	for i, v := range th.amo {
+		for _, w := range th.amo[i+1:] {
+			cs = append(cs, []dimacs.Lit{-dimacs.Lit(v), -dimacs.Lit(w)})
+		}
+	}
+	n := len(th.xor)
+	for bits := 0; bits < 1<<uint(n); bits++ {
+		var c []dimacs.Lit
+		odd := false
+		for i, v := range th.xor {
+			if bits&(1<<uint(i)) != 0 {
+				odd = !odd
+				c = append(c, -dimacs.Lit(v))
+			} else {
+				c = append(c, dimacs.Lit(v))
+			}
+		}
+		if !odd {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+type propagatorSolver interface {
+	Solver
+	ConnectPropagator(p Propagator)
+	ObserveVar(v Var)
+	Model() []LBool
+}
+
+func TestDPLL_ConnectPropagator(t *testing.T) {
+	for seed := int64(1); seed < 40; seed++ {
+		p := randomProblem(seed, 30, 110)
+		th := newTestTheory([]Var{1, 2, 3, 4, 5, 6, 7, 8}, []Var{9, 10, 11, 12, 13, 14})
+
+		encoded := &dimacs.Problem{NumVar: p.NumVar, Clauses: append(th.encode(), p.Clauses...)}
+		check := New(nil)
+		addProblem(check, encoded)
+		want := check.Solve()
+
+		for _, s := range []propagatorSolver{New(nil), NewSimp(nil, nil)} {
+			th := newTestTheory(th.amo, th.xor)
+			addProblem(s, p)
+			s.ConnectPropagator(th)
+			for _, v := range append(th.amo, th.xor...) {
+				s.ObserveVar(v)
+			}
+			sat := s.Solve()
+			if sat != want {
+				t.Errorf("seed %d: sat %v (!= %v)", seed, sat, want)
+				continue
+			}
+			if sat {
+				checkModel(t, "theory", encoded, s.Model())
+			}
+			if th.nassign == 0 || th.nbacktrack == 0 {
+				t.Errorf("seed %d: %d assignments %d backtracks", seed, th.nassign, th.nbacktrack)
+			}
+		}
+	}
+}
+
+func TestSimp_ObserveVar(t *testing.T) {
+	s := NewSimp(nil, nil)
+	for i := 0; i < 3; i++ {
+		s.NewVar(LUndef, true)
+	}
+	s.AddClause(LiteralInt(1), LiteralInt(2))
+	s.AddClause(LiteralInt(-2), LiteralInt(3))
+	s.ObserveVar(2)
+	if !s.SolveSimp(nil, true, false) {
+		t.Fatalf("unsatisfiable")
+	}
+	if s.IsEliminated(2) {
+		t.Errorf("observed variable eliminated")
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+/*
+Package qbf decides quantified boolean formulas in prenex CNF.
+
+Problems of the form exists X. forall Y. F(X, Y), where F is in CNF, are
+solved by counterexample guided abstraction refinement.  An abstraction solver
+proposes an assignment to X.  A counterexample solver assumes the assignment
+and searches for an assignment to Y which falsifies a clause of F.  Each
+counterexample y refines the abstraction with the clauses of F(X, y).  The
+problem is true when no counterexample exists, and the last assignment to X is
+a certificate.
+*/
+package qbf
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// ErrPrefix is returned by New for problems whose quantifier prefix is not
+// of the form exists-forall.
+var ErrPrefix = errors.New("qbf: prefix is not exists-forall")
+
+// Solver decides a 2QBF problem.
+type Solver struct {
+	p      *dimacs.QProblem
+	forall []bool // universally quantified variables
+	exists []dpll.Var
+
+	abs *dpll.DPLL // proposes assignments to the existential variables
+	cex *dpll.DPLL // finds assignments to the universal variables falsifying a clause
+
+	mut         sync.Mutex
+	interrupted bool
+
+	cert  []dpll.Lit
+	niter int
+}
+
+// New returns a Solver for p, whose quantifier prefix must consist of at
+// most one existential block followed by at most one universal block.  The
+// solvers used internally are created with opt.
+func New(p *dimacs.QProblem, opt *dpll.Opt) (*Solver, error) {
+	s := &Solver{
+		p:      p,
+		forall: make([]bool, p.NumVar+1),
+		abs:    dpll.New(opt),
+		cex:    dpll.New(opt),
+	}
+	for i, b := range p.Prefix {
+		switch {
+		case b.Quant == dimacs.Forall:
+			for _, v := range b.Vars {
+				s.forall[v] = true
+			}
+		case i > 0:
+			return nil, ErrPrefix
+		}
+	}
+	for v := 1; v <= p.NumVar; v++ {
+		if !s.forall[v] {
+			s.exists = append(s.exists, dpll.Var(v))
+		}
+	}
+
+	// universal variables are only decided by the counterexample solver
+	for v := 1; v <= p.NumVar; v++ {
+		s.abs.NewVar(dpll.LUndef, !s.forall[v])
+		s.cex.NewVar(dpll.LUndef, true)
+	}
+
+	// the counterexample solver selects a clause with a universal literal
+	// and falsifies it.  Clauses without universal literals are enforced by
+	// the abstraction.
+	var selectors []dpll.Lit
+	for _, c := range p.Clauses {
+		ps := literals(c)
+		if !s.isUniversal(ps) {
+			s.abs.AddClause(ps...)
+			continue
+		}
+		sel := dpll.Literal(s.cex.NewVar(dpll.LUndef, true), false)
+		for _, p := range ps {
+			s.cex.AddClause(sel.Inverse(), p.Inverse())
+		}
+		selectors = append(selectors, sel)
+	}
+	s.cex.AddClause(selectors...)
+
+	return s, nil
+}
+
+func literals(c []dimacs.Lit) []dpll.Lit {
+	ps := make([]dpll.Lit, len(c))
+	for i, x := range c {
+		ps[i] = dpll.LiteralInt(int(x))
+	}
+	return ps
+}
+
+func (s *Solver) isUniversal(ps []dpll.Lit) bool {
+	for _, p := range ps {
+		if s.forall[p.Var()] {
+			return true
+		}
+	}
+	return false
+}
+
+// Interrupt stops Solve from another goroutine.
+func (s *Solver) Interrupt() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.interrupted = true
+	s.abs.Interrupt()
+	s.cex.Interrupt()
+}
+
+// Solve returns LTrue if the problem is true, LFalse if it is false, and
+// LUndef if the search was interrupted.
+func (s *Solver) Solve() dpll.LBool {
+	s.cert = nil
+	for {
+		s.mut.Lock()
+		interrupted := s.interrupted
+		s.mut.Unlock()
+		if interrupted {
+			return dpll.LUndef
+		}
+		s.niter++
+
+		status := s.abs.SolveLimited()
+		if !status.IsTrue() {
+			return status
+		}
+		model := s.abs.Model()
+		assump := make([]dpll.Lit, len(s.exists))
+		for i, v := range s.exists {
+			assump[i] = dpll.Literal(v, !model[v].IsTrue())
+		}
+
+		switch status := s.cex.SolveLimited(assump...); {
+		case status.IsFalse():
+			s.cert = assump
+			return dpll.LTrue
+		case status.IsUndef():
+			return dpll.LUndef
+		}
+		if !s.refine(s.cex.Model()) {
+			return dpll.LFalse
+		}
+	}
+}
+
+// refine adds the clauses of the problem instantiated with the universal
+// assignment in model to the abstraction.  refine returns false if the
+// abstraction becomes unsatisfiable.
+func (s *Solver) refine(model []dpll.LBool) bool {
+	ok := true
+nextClause:
+	for _, c := range s.p.Clauses {
+		ps := literals(c)
+		if !s.isUniversal(ps) {
+			continue
+		}
+		qs := ps[:0]
+		for _, p := range ps {
+			if !s.forall[p.Var()] {
+				qs = append(qs, p)
+			} else if model[p.Var()].Xor(p.IsNeg()).IsTrue() {
+				continue nextClause
+			}
+		}
+		ok = s.abs.AddClause(qs...) && ok
+	}
+	return ok
+}
+
+// Certificate returns an assignment to the existential variables under which
+// the problem is true, if the last call to Solve returned LTrue.
+func (s *Solver) Certificate() []dpll.Lit {
+	return s.cert
+}
+
+// Iterations returns the number of abstractions proposed by Solve.
+func (s *Solver) Iterations() int {
+	return s.niter
+}
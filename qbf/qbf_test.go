@@ -0,0 +1,182 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package qbf
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/bmatsuo/dpll"
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// randomProblem returns a problem over nx existential variables followed by
+// ny universal variables.
+func randomProblem(seed int64, nx, ny, nclause int) *dimacs.QProblem {
+	r := rand.New(rand.NewSource(seed))
+	p := &dimacs.QProblem{}
+	p.NumVar = nx + ny
+	b := dimacs.QuantBlock{Quant: dimacs.Exists}
+	for v := 1; v <= nx; v++ {
+		b.Vars = append(b.Vars, v)
+	}
+	p.Prefix = append(p.Prefix, b)
+	b = dimacs.QuantBlock{Quant: dimacs.Forall}
+	for v := nx + 1; v <= nx+ny; v++ {
+		b.Vars = append(b.Vars, v)
+	}
+	p.Prefix = append(p.Prefix, b)
+	for len(p.Clauses) < nclause {
+		var c []dimacs.Lit
+		for len(c) < 3 {
+			x := dimacs.Lit(r.Intn(p.NumVar) + 1)
+			if r.Intn(2) == 0 {
+				x = -x
+			}
+			c = append(c, x)
+		}
+		p.Clauses = append(p.Clauses, c)
+	}
+	return p
+}
+
+// holds returns true if every clause of p is satisfied when the first nx
+// variables are assigned by x and the remaining variables by y.
+func holds(p *dimacs.QProblem, nx int, x, y uint) bool {
+	for _, c := range p.Clauses {
+		sat := false
+		for _, lit := range c {
+			v := lit.Var()
+			var val bool
+			if v <= nx {
+				val = x&(1<<uint(v-1)) != 0
+			} else {
+				val = y&(1<<uint(v-nx-1)) != 0
+			}
+			if val != lit.Neg() {
+				sat = true
+				break
+			}
+		}
+		if !sat {
+			return false
+		}
+	}
+	return true
+}
+
+func forall(p *dimacs.QProblem, nx, ny int, x uint) bool {
+	for y := uint(0); y < 1<<uint(ny); y++ {
+		if !holds(p, nx, x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSolver(t *testing.T) {
+	const nx, ny = 5, 4
+	ntrue := 0
+	for seed := int64(1); seed < 100; seed++ {
+		p := randomProblem(seed, nx, ny, 6+int(seed%6))
+		expect := false
+		for x := uint(0); x < 1<<nx; x++ {
+			expect = expect || forall(p, nx, ny, x)
+		}
+
+		s, err := New(p, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status := s.Solve()
+		if status.IsTrue() != expect || status.IsUndef() {
+			t.Errorf("seed %d: status %v (expected %v)", seed, status, expect)
+			continue
+		}
+		if !expect {
+			continue
+		}
+		ntrue++
+
+		cert := s.Certificate()
+		if len(cert) != nx {
+			t.Errorf("seed %d: certificate %v", seed, cert)
+			continue
+		}
+		var x uint
+		for _, p := range cert {
+			if !p.IsNeg() {
+				x |= 1 << uint(p.Var()-1)
+			}
+		}
+		if !forall(p, nx, ny, x) {
+			t.Errorf("seed %d: invalid certificate %v", seed, cert)
+		}
+	}
+	if ntrue == 0 {
+		t.Errorf("no true problems")
+	}
+}
+
+func TestSolver_qdimacs(t *testing.T) {
+	// exists x forall y. (x or y) and (x or -y) is true with x
+	in := "p cnf 2 2\ne 1 0\na 2 0\n1 2 0\n1 -2 0\n"
+	p, err := dimacs.DecodeQProblem(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := s.Solve(); !status.IsTrue() {
+		t.Fatalf("status %v", status)
+	}
+	if cert := s.Certificate(); len(cert) != 1 || cert[0] != dpll.LiteralInt(1) {
+		t.Errorf("certificate %v", cert)
+	}
+
+	// exists x forall y. (x or y) and (-x or -y) is false
+	in = "p cnf 2 2\ne 1 0\na 2 0\n1 2 0\n-1 -2 0\n"
+	p, err = dimacs.DecodeQProblem(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = New(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := s.Solve(); !status.IsFalse() {
+		t.Fatalf("status %v", status)
+	}
+}
+
+func TestNew_prefix(t *testing.T) {
+	for _, in := range []string{
+		"p cnf 2 1\na 1 0\ne 2 0\n1 2 0\n",
+		"p cnf 3 1\ne 1 0\na 2 0\ne 3 0\n1 2 3 0\n",
+	} {
+		p, err := dimacs.DecodeQProblem(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New(p, nil); err != ErrPrefix {
+			t.Errorf("%q: %v", in, err)
+		}
+	}
+}
+
+func TestSolver_Interrupt(t *testing.T) {
+	s, err := New(randomProblem(1, 5, 4, 8), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Interrupt()
+	if status := s.Solve(); !status.IsUndef() {
+		t.Errorf("status %v", status)
+	}
+}
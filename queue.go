@@ -74,3 +74,16 @@ func (q *clauseQueue) Insert(c *Clause) {
 		q.end = n + n2
 	}
 }
+
+// clone returns a copy of q holding the clauses returned by clone.
+func (q *clauseQueue) clone(clone func(*Clause) *Clause) *clauseQueue {
+	q2 := &clauseQueue{
+		q:     make([]*Clause, len(q.q)),
+		start: q.start,
+		end:   q.end,
+	}
+	for i, c := range q.q {
+		q2.q[i] = clone(c)
+	}
+	return q2
+}
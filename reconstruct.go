@@ -0,0 +1,250 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// Reconstruction holds what is needed to extend a model of a problem
+// returned by Simp.Simplified to a model of the original problem, which
+// allows the simplified problem to be solved by another solver.
+type Reconstruction struct {
+	NumVar int     // number of variables in the original problem
+	Map    []Var   // Map[v] is the original variable of simplified variable v; Map[0] is unused
+	Units  []Lit   // original literals assigned at the top level
+	Elim   [][]Lit // eliminated clauses in the order of elimination, each beginning with the literal of its eliminated variable
+}
+
+// Simplified returns the clauses of s with the variables renumbered
+// compactly, along with the Reconstruction of models of the original clauses.
+// Satisfied clauses and false literals are removed, so only variables which
+// are neither eliminated nor assigned at the top level occur in the problem.
+// If s is not Okay the problem contains a single empty clause.  Simplified
+// must not be called during a search.
+//
+//		s.Eliminate(true)
+//		p, rec := s.Simplified()
+//		// solve p with any solver and let model be its result
+//		model = rec.Extend(model)
+func (s *Simp) Simplified() (*dimacs.Problem, *Reconstruction) {
+	d := s.d
+	if d.decisionLevel() != 0 {
+		panic("non-root decision level")
+	}
+	rec := &Reconstruction{NumVar: d.NumVar(), Map: []Var{0}}
+	rec.Units = append(rec.Units, d.trail...)
+	for i := len(s.elimClauses) - 1; i > 0; {
+		n := int(s.elimClauses[i])
+		c := make([]Lit, n)
+		for j := range c {
+			c[j] = Lit(s.elimClauses[i-n+j])
+		}
+		rec.Elim = append(rec.Elim, c)
+		i -= n + 1
+	}
+	for i, j := 0, len(rec.Elim)-1; i < j; i, j = i+1, j-1 {
+		rec.Elim[i], rec.Elim[j] = rec.Elim[j], rec.Elim[i]
+	}
+
+	p := &dimacs.Problem{}
+	if !d.ok {
+		p.Clauses = [][]dimacs.Lit{{}}
+		return p, rec
+	}
+	renum := make([]int, d.NumVar()+1)
+	for _, c := range d.clauses {
+		if c.Mark.HasAny(MarkDel) || d.satisfied(c) {
+			continue
+		}
+		var dc []dimacs.Lit
+		for _, q := range c.Lit {
+			if d.ValueLit(q).IsFalse() {
+				continue
+			}
+			v := q.Var()
+			if renum[v] == 0 {
+				rec.Map = append(rec.Map, v)
+				renum[v] = len(rec.Map) - 1
+			}
+			x := dimacs.Lit(renum[v])
+			if q.IsNeg() {
+				x = -x
+			}
+			dc = append(dc, x)
+		}
+		p.Clauses = append(p.Clauses, dc)
+	}
+	p.NumVar = len(rec.Map) - 1
+	return p, rec
+}
+
+// Extend returns a model of the original problem given a model of the
+// simplified problem, indexed by simplified variable.  Variables of the
+// simplified problem which model leaves unassigned are taken to be false, as
+// are original variables which occur in no clause.
+func (rec *Reconstruction) Extend(model []LBool) []LBool {
+	ext := make([]LBool, rec.NumVar+1)
+	for v := range ext {
+		ext[v] = LFalse
+	}
+	for _, c := range rec.Elim {
+		ext[c[0].Var()] = LUndef
+	}
+	for _, p := range rec.Units {
+		ext[p.Var()] = LiftBool(!p.IsNeg())
+	}
+	for v := 1; v < len(rec.Map); v++ {
+		ext[rec.Map[v]] = LFalse
+		if v < len(model) && model[v].IsTrue() {
+			ext[rec.Map[v]] = LTrue
+		}
+	}
+	// as in Simp.extendModel the clauses are visited in reverse, so each
+	// eliminated variable is assigned before the clauses of the variables
+	// eliminated earlier are checked.
+nextClause:
+	for i := len(rec.Elim) - 1; i >= 0; i-- {
+		c := rec.Elim[i]
+		for _, q := range c[1:] {
+			if !ext[q.Var()].Xor(q.IsNeg()).IsFalse() {
+				continue nextClause
+			}
+		}
+		ext[c[0].Var()] = LiftBool(!c[0].IsNeg())
+	}
+	return ext
+}
+
+// Write writes rec to w in a text format resembling DIMACS.  The header gives
+// the number of original and simplified variables and is followed by the
+// variable map, the top level assignments and one line for each eliminated
+// clause.
+//
+//		p rec 5 2
+//		m 2 4 0
+//		u -1 0
+//		e 3 -2 0
+//		e -3 0
+func (rec *Reconstruction) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p rec %d %d\n", rec.NumVar, len(rec.Map)-1)
+	bw.WriteString("m")
+	for _, v := range rec.Map[1:] {
+		bw.WriteByte(' ')
+		bw.WriteString(strconv.Itoa(int(v)))
+	}
+	bw.WriteString(" 0\n")
+	writeLits := func(prefix string, ps []Lit) {
+		bw.WriteString(prefix)
+		for _, p := range ps {
+			bw.WriteByte(' ')
+			bw.WriteString(p.String())
+		}
+		bw.WriteString(" 0\n")
+	}
+	writeLits("u", rec.Units)
+	for _, c := range rec.Elim {
+		writeLits("e", c)
+	}
+	return bw.Flush()
+}
+
+// ReadReconstruction reads a Reconstruction in the format written by
+// Reconstruction.Write.  Malformed input is reported with a *dimacs.Error.
+func ReadReconstruction(r io.Reader) (*Reconstruction, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<30)
+	rec := &Reconstruction{}
+	var line int
+	var header, mapped bool
+	var nsimp int
+	errorf := func(kind dimacs.ErrorKind, tok string, format string, v ...interface{}) error {
+		return &dimacs.Error{Kind: kind, Line: line, Column: 1, Token: tok, Msg: fmt.Sprintf(format, v...)}
+	}
+	for sc.Scan() {
+		line++
+		fs := strings.Fields(sc.Text())
+		if len(fs) == 0 || fs[0] == "c" {
+			continue
+		}
+		if !header {
+			if len(fs) != 4 || fs[0] != "p" || fs[1] != "rec" {
+				return nil, errorf(dimacs.ErrHeader, sc.Text(), "invalid reconstruction header")
+			}
+			var err1, err2 error
+			rec.NumVar, err1 = strconv.Atoi(fs[2])
+			nsimp, err2 = strconv.Atoi(fs[3])
+			if err1 != nil || err2 != nil || rec.NumVar < 0 || nsimp < 0 || uint(rec.NumVar) > VarMax {
+				return nil, errorf(dimacs.ErrHeader, sc.Text(), "invalid reconstruction header")
+			}
+			header = true
+			continue
+		}
+		if fs[len(fs)-1] != "0" {
+			return nil, errorf(dimacs.ErrTermination, "", "missing terminating null")
+		}
+		var xs []int
+		for _, f := range fs[1 : len(fs)-1] {
+			x, err := strconv.Atoi(f)
+			if err != nil || x == 0 {
+				return nil, errorf(dimacs.ErrLiteral, f, "failed to parse literal")
+			}
+			if x > rec.NumVar || -x > rec.NumVar {
+				return nil, errorf(dimacs.ErrRange, f, "variable outside of range")
+			}
+			xs = append(xs, x)
+		}
+		switch fs[0] {
+		case "m":
+			if mapped {
+				return nil, errorf(dimacs.ErrOrder, "m", "multiple variable maps")
+			}
+			if len(xs) != nsimp {
+				return nil, errorf(dimacs.ErrRange, "m", "variable map of %d variables (expected %d)", len(xs), nsimp)
+			}
+			mapped = true
+			rec.Map = make([]Var, 1, nsimp+1)
+			for _, x := range xs {
+				if x < 0 {
+					return nil, errorf(dimacs.ErrLiteral, strconv.Itoa(x), "negative variable in map")
+				}
+				rec.Map = append(rec.Map, Var(x))
+			}
+		case "u":
+			for _, x := range xs {
+				rec.Units = append(rec.Units, LiteralInt(x))
+			}
+		case "e":
+			if len(xs) == 0 {
+				return nil, errorf(dimacs.ErrLiteral, "e", "empty eliminated clause")
+			}
+			c := make([]Lit, len(xs))
+			for i, x := range xs {
+				c[i] = LiteralInt(x)
+			}
+			rec.Elim = append(rec.Elim, c)
+		default:
+			return nil, errorf(dimacs.ErrOrder, fs[0], "invalid line")
+		}
+	}
+	if sc.Err() != nil {
+		return nil, sc.Err()
+	}
+	if !header {
+		return nil, errorf(dimacs.ErrHeader, "", "missing reconstruction header")
+	}
+	if !mapped {
+		return nil, errorf(dimacs.ErrOrder, "", "missing variable map")
+	}
+	return rec, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func TestSimp_Simplified(t *testing.T) {
+	var nsat, nelim int
+	for seed := int64(0); seed < 50; seed++ {
+		name := fmt.Sprintf("seed %d", seed)
+		p := randomProblem(seed, 40, 150)
+		s := NewSimp(nil, nil)
+		addProblem(s, p)
+		s.Eliminate(true)
+		nelim += s.Stats().EliminatedVars
+
+		sp, rec := s.Simplified()
+		if rec.NumVar != p.NumVar {
+			t.Errorf("%s: %d original variables (expected %d)", name, rec.NumVar, p.NumVar)
+		}
+		for _, c := range sp.Clauses {
+			for _, x := range c {
+				if x.Var() < 1 || x.Var() > sp.NumVar {
+					t.Fatalf("%s: literal %d outside the simplified problem", name, x)
+				}
+			}
+		}
+
+		var buf bytes.Buffer
+		err := rec.Write(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec2, err := ReadReconstruction(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(rec2, rec) {
+			t.Errorf("%s: read %v (expected %v)", name, rec2, rec)
+		}
+
+		d := New(nil)
+		addProblem(d, sp)
+		status := d.SolveLimited()
+		if status.IsTrue() != solvable(p) {
+			t.Errorf("%s: simplified problem is %v", name, status)
+			continue
+		}
+		if status.IsTrue() {
+			nsat++
+			model := rec2.Extend(d.Model())
+			if len(model) != p.NumVar+1 {
+				t.Fatalf("%s: extended model of length %d", name, len(model))
+			}
+			checkModel(t, name, p, model)
+		}
+	}
+	if nsat == 0 || nelim == 0 {
+		t.Errorf("%d satisfiable problems, %d eliminated variables", nsat, nelim)
+	}
+}
+
+// solvable reports whether p is satisfiable according to an unsimplified
+// solver.
+func solvable(p *dimacs.Problem) bool {
+	d := New(nil)
+	addProblem(d, p)
+	return d.Solve()
+}
+
+func TestSimp_Simplified_unsat(t *testing.T) {
+	s := NewSimp(nil, nil)
+	_, err := DecodeFile(s, "testdata/factoring_2_3_UNSAT.cnf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Eliminate(true) {
+		t.Skip("unsatisfiability not detected by simplification")
+	}
+	p, _ := s.Simplified()
+	if len(p.Clauses) != 1 || len(p.Clauses[0]) != 0 {
+		t.Errorf("clauses %v (expected one empty clause)", p.Clauses)
+	}
+}
+
+func TestReadReconstruction_error(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		kind  dimacs.ErrorKind
+	}{
+		{"", dimacs.ErrHeader},
+		{"p cnf 1 1\n", dimacs.ErrHeader},
+		{"p rec 2 1\n", dimacs.ErrOrder},
+		{"p rec 2 1\nm 1 2 0\n", dimacs.ErrRange},
+		{"p rec 2 1\nm 3 0\n", dimacs.ErrRange},
+		{"p rec 2 1\nm 1\n", dimacs.ErrTermination},
+		{"p rec 2 1\nm 1 0\nm 1 0\n", dimacs.ErrOrder},
+		{"p rec 2 1\nm 1 0\ne 0\n", dimacs.ErrLiteral},
+		{"p rec 2 1\nm 1 0\nu x 0\n", dimacs.ErrLiteral},
+		{"p rec 2 1\nm 1 0\nx 1 0\n", dimacs.ErrOrder},
+	} {
+		_, err := ReadReconstruction(strings.NewReader(test.input))
+		var derr *dimacs.Error
+		if !errors.As(err, &derr) {
+			t.Errorf("%q: unexpected error: %v", test.input, err)
+			continue
+		}
+		if derr.Kind != test.kind {
+			t.Errorf("%q: %s error (expected %s)", test.input, derr.Kind, test.kind)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"fmt"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// IncrementalSolver is a Solver which reports the assumptions responsible for
+// an unsatisfiable result.  DPLL and Simp implement IncrementalSolver.
+type IncrementalSolver interface {
+	Solver
+	Conflict() []Lit
+}
+
+var _ IncrementalSolver = (*DPLL)(nil)
+var _ IncrementalSolver = (*Simp)(nil)
+
+// ReplayResult is the outcome of a solve step of an incremental problem.
+type ReplayResult struct {
+	Step     int   // index of the step in the problem
+	Status   LBool // result of SolveLimited
+	Conflict []Lit // a copy of Conflict() when Status is LFalse
+}
+
+// Replay feeds the steps of an iCNF trace into s in order, adding clauses with
+// AddClause and solving with SolveLimited under the assumptions of each solve
+// step, and returns the result of each solve.  Variables are created as
+// needed.  A contradiction among the clauses does not stop the replay, the
+// following solves are simply unsatisfiable.  Any budget set on s applies to
+// each solve.
+//
+//		p, err := dimacs.DecodeIncFile("trace.icnf")
+//		// ...
+//		results, err := dpll.Replay(dpll.New(nil), p)
+func Replay(s IncrementalSolver, p *dimacs.IncProblem) ([]ReplayResult, error) {
+	if uint(p.NumVar) > VarMax {
+		return nil, fmt.Errorf("variable %d outside acceptable range", p.NumVar)
+	}
+	var results []ReplayResult
+	for i, step := range p.Steps {
+		ps := make([]Lit, len(step.Lits))
+		for j, x := range step.Lits {
+			ps[j] = LiteralInt(int(x))
+			for ps[j].Var() > Var(s.NumVar()) {
+				s.NewVar(LUndef, true)
+			}
+		}
+		if !step.Solve {
+			s.AddClause(ps...)
+			continue
+		}
+		r := ReplayResult{Step: i, Status: s.SolveLimited(ps...)}
+		if r.Status.IsFalse() {
+			r.Conflict = append([]Lit{}, s.Conflict()...)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func TestReplay(t *testing.T) {
+	trace := "p inccnf\n1 2 0\na -1 0\n-2 3 0\na -1 -3 0\na 0\n-1 0\n-3 0\na 0\na 2 0\n"
+	p, err := dimacs.DecodeIncProblem(strings.NewReader(trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []IncrementalSolver{New(nil), NewSimp(nil, nil)} {
+		results, err := Replay(s, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []ReplayResult{
+			{Step: 1, Status: LTrue},
+			{Step: 3, Status: LFalse},
+			{Step: 4, Status: LTrue},
+			{Step: 7, Status: LFalse},
+			{Step: 8, Status: LFalse},
+		}
+		if len(results) != len(expect) {
+			t.Fatalf("%T: %d results", s, len(results))
+		}
+		for i, r := range results {
+			if r.Step != expect[i].Step || r.Status != expect[i].Status {
+				t.Errorf("%T: result %d: %v (!= %v)", s, i, r, expect[i])
+			}
+		}
+
+		// the conflict is expressed in the negated assumptions
+		conflict := append([]Lit(nil), results[1].Conflict...)
+		if len(conflict) == 2 && conflict[0] > conflict[1] {
+			conflict[0], conflict[1] = conflict[1], conflict[0]
+		}
+		if !reflect.DeepEqual(conflict, []Lit{LiteralInt(1), LiteralInt(3)}) {
+			t.Errorf("%T: conflict %v", s, results[1].Conflict)
+		}
+	}
+}
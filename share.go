@@ -0,0 +1,83 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"fmt"
+	"sort"
+)
+
+// exportLearnt passes a clause learnt in search to ExportLearnt.
+func (d *DPLL) exportLearnt(ps []Lit, lbd int) {
+	if d.ExportLearnt == nil || (d.ExportMaxLen > 0 && len(ps) > d.ExportMaxLen) {
+		return
+	}
+	d.nexported++
+	d.ExportLearnt(ps, lbd)
+}
+
+// importLearnt adds the clauses returned by ImportLearnt as learnt clauses.
+// Imported clauses must be implied by the problem and may be removed like any
+// other learnt clause.  importLearnt returns false if the clause set was found
+// to be unsatisfiable.
+func (d *DPLL) importLearnt() bool {
+	if d.ImportLearnt == nil {
+		return true
+	}
+	if d.decisionLevel() != 0 {
+		panic("non-root decision level")
+	}
+	for _, ps := range d.ImportLearnt() {
+		if !d.addLearnt(ps) {
+			d.ok = false
+			return false
+		}
+	}
+	if d.propagate() != nil {
+		d.ok = false
+		return false
+	}
+	return true
+}
+
+// addLearnt adds a copy of ps as a learnt clause at the root level.  Like
+// addClause, satisfied clauses are ignored and false literals are removed.
+// addLearnt returns false if the clause is empty after removing false
+// literals.
+func (d *DPLL) addLearnt(ps []Lit) bool {
+	c := make([]Lit, len(ps))
+	copy(c, ps)
+	sort.Sort(litSlice(c))
+
+	var j int
+	for i, p := 0, LitUndef; i < len(c); i++ {
+		if int(c[i].Var()) > d.NumVar() {
+			panic(fmt.Sprintf("unknown variable: %d", c[i].Var()))
+		}
+		if d.ValueLit(c[i]).IsTrue() || c[i] == p.Inverse() {
+			return true
+		} else if !d.ValueLit(c[i]).IsFalse() && c[i] != p {
+			p = c[i]
+			c[j] = p
+			j++
+		}
+	}
+	c = c[:j]
+
+	d.nimported++
+	switch len(c) {
+	case 0:
+		return false
+	case 1:
+		d.uncheckedEnqueue(c[0], nil)
+	default:
+		cl := d.newClause(c, true)
+		cl.LBD = uint32(len(c))
+		d.learnt = append(d.learnt, cl)
+		d.attachClause(cl)
+	}
+	return true
+}
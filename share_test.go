@@ -0,0 +1,116 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import "testing"
+
+func TestDPLL_ExportLearnt(t *testing.T) {
+	const maxLen = 8
+	p := randomProblem(1, 60, 300)
+	var learnt [][]Lit
+	d := New(&Opt{
+		ExportMaxLen: maxLen,
+		ExportLearnt: func(ps []Lit, lbd int) {
+			if lbd < 1 || lbd > len(ps) {
+				t.Errorf("lbd %d for clause %v", lbd, ps)
+			}
+			learnt = append(learnt, append([]Lit(nil), ps...))
+		},
+	})
+	addProblem(d, p)
+	if d.Solve() {
+		t.Fatalf("satisfiable")
+	}
+	if len(learnt) == 0 {
+		t.Fatalf("no clauses exported")
+	}
+
+	// exported clauses are implied by the problem
+	check := New(nil)
+	addProblem(check, p)
+	for _, ps := range learnt {
+		if len(ps) > maxLen {
+			t.Errorf("clause length %d (> %d)", len(ps), maxLen)
+		}
+		assump := make([]Lit, len(ps))
+		for i, p := range ps {
+			assump[i] = p.Inverse()
+		}
+		if check.Solve(assump...) {
+			t.Errorf("clause not implied: %v", ps)
+		}
+	}
+}
+
+func TestDPLL_ImportLearnt(t *testing.T) {
+	for seed := int64(1); seed < 10; seed++ {
+		p := randomProblem(seed, 80, 340)
+
+		var learnt [][]Lit
+		d1 := New(&Opt{
+			RestartFirst: 10,
+			ExportLearnt: func(ps []Lit, lbd int) {
+				learnt = append(learnt, append([]Lit(nil), ps...))
+			},
+		})
+		d2 := New(&Opt{
+			RestartFirst: 10,
+			RandSeed:     seed,
+			ImportLearnt: func() [][]Lit {
+				imported := learnt
+				learnt = nil
+				return imported
+			},
+		})
+		addProblem(d1, p)
+		addProblem(d2, p)
+
+		// interleave the searches so that d2 imports the clauses of d1
+		st1, st2 := LUndef, LUndef
+		for st1.IsUndef() && st2.IsUndef() {
+			d1.conflictBudget = int64(d1.nconflicts) + 50
+			st1 = d1.SolveLimited()
+			d2.conflictBudget = int64(d2.nconflicts) + 50
+			st2 = d2.SolveLimited()
+		}
+		if !st1.IsUndef() && !st2.IsUndef() && st1 != st2 {
+			t.Errorf("seed %d: status %v (!= %v)", seed, st2, st1)
+		}
+		if st2.IsTrue() {
+			checkModel(t, "import", p, d2.Model())
+		}
+		if st2.IsUndef() {
+			d2.budgetOff()
+			st2 = d2.SolveLimited()
+		}
+		d := New(nil)
+		addProblem(d, p)
+		if sat := d.Solve(); sat != st2.IsTrue() {
+			t.Errorf("seed %d: sat %v (!= %v)", seed, st2, sat)
+		}
+		if d2.nimported == 0 {
+			t.Errorf("seed %d: no clauses imported", seed)
+		}
+	}
+}
+
+func TestDPLL_importLearnt_unsat(t *testing.T) {
+	d := New(&Opt{
+		ImportLearnt: func() [][]Lit {
+			return [][]Lit{{LiteralInt(-1)}}
+		},
+	})
+	d.NewVar(LUndef, true)
+	d.NewVar(LUndef, true)
+	d.AddClause(LiteralInt(1), LiteralInt(2))
+	d.AddClause(LiteralInt(1), LiteralInt(-2))
+	if d.importLearnt() {
+		t.Errorf("conflict not detected")
+	}
+	if d.Okay() {
+		t.Errorf("ok")
+	}
+}
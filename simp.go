@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 )
 
 // Marks used by the Simp solver
@@ -22,16 +23,26 @@ type SimpOpt struct {
 	RCheck           bool    // Check if a clause is already implied (costly)
 	NoElim           bool    // Do not perform variable elimination
 	NoExtend         bool    // When true the caller does not need to know the full model
+	NoGates          bool    // Do not use gate definitions to limit resolvents during variable elimination
 	Grow             int     // Allow a variable elimination step to grow by the given number of clauses
 	ClauseLimit      int     // Variables are not eliminated if it produces a resolvent with a length above this limit
 	SubsumptionLimit int     // Do not check if subsumption against a clause larger than this
 	SimpGarbageFrac  float64 // The fraction of wasted memory allowed before a garbage collection is triggered during simplification
+
+	Inprocess         bool    // Periodically simplify the clauses again during search
+	InprocessInterval int     // Number of conflicts between inprocessing rounds
+	InprocessEffort   float64 // Simplification steps allowed in a round as a fraction of propagations since the last round
+
+	VerifyModel bool // Panic if an extended model does not satisfy the clauses given to AddClause (costly)
 }
 
 var simpOptDefault = &SimpOpt{
 	ClauseLimit:      20,
 	SubsumptionLimit: 1000,
 	SimpGarbageFrac:  0.5,
+
+	InprocessInterval: 5000,
+	InprocessEffort:   0.1,
 }
 
 func mergeSimpOpt(o1, o2 *SimpOpt) *SimpOpt {
@@ -52,6 +63,9 @@ func mergeSimpOpt(o1, o2 *SimpOpt) *SimpOpt {
 	if o2.NoExtend {
 		o.NoExtend = true
 	}
+	if o2.NoGates {
+		o.NoGates = true
+	}
 	if o2.Grow != 0 {
 		o.Grow = o2.Grow
 	}
@@ -64,6 +78,18 @@ func mergeSimpOpt(o1, o2 *SimpOpt) *SimpOpt {
 	if o2.SimpGarbageFrac != 0 {
 		o.SubsumptionLimit = o2.SubsumptionLimit
 	}
+	if o2.Inprocess {
+		o.Inprocess = true
+	}
+	if o2.InprocessInterval != 0 {
+		o.InprocessInterval = o2.InprocessInterval
+	}
+	if o2.InprocessEffort != 0 {
+		o.InprocessEffort = o2.InprocessEffort
+	}
+	if o2.VerifyModel {
+		o.VerifyModel = true
+	}
 	return o
 }
 
@@ -75,6 +101,14 @@ type Simp struct {
 	nmerge    int
 	nasymmlit int
 	nelimvars int
+	nsubcheck int
+	ngates    [numGateKind]int
+
+	// inprocessing state
+	ninprocess    int
+	inprocessConf uint64 // conflicts at which the next round may start
+	inprocessProp uint64 // propagations at the end of the last round
+	simpBudget    int    // simplification steps allowed; negative for no limit
 
 	// solver state
 
@@ -119,6 +153,7 @@ func NewSimp(opt *Opt, simpOpt *SimpOpt) *Simp {
 
 	s := &Simp{
 		SimpOpt:       *mergeSimpOpt(simpOptDefault, simpOpt),
+		simpBudget:    -1,
 		elimOrder:     1,
 		useSimp:       true,
 		bwdsubTmpUnit: d.newClause([]Lit{0}, false),
@@ -131,11 +166,20 @@ func NewSimp(opt *Opt, simpOpt *SimpOpt) *Simp {
 		d:             d,
 	}
 	s.elimHeap = newElimQueue(&s.numOcc)
+	s.inprocessConf = uint64(s.InprocessInterval)
+
+	// models are verified after they are extended to eliminated variables
+	if d.VerifyModel {
+		s.VerifyModel = true
+		d.VerifyModel = false
+	}
+	d.retain = s.VerifyModel
 
 	// override standard DPLL methods
-	d.addClauseFn = s.AddClause
+	d.addClauseFn = s.addClause
 	d.removeClauseFn = s.removeClause
 	d.garbageCollectFn = s.garbageCollect
+	d.inprocessFn = s.inprocess
 
 	return s
 }
@@ -155,7 +199,7 @@ func (s *Simp) NewVar(upol LBool, dvar bool) Var {
 	v := s.d.NewVar(upol, dvar)
 
 	s.frozen = append(s.frozen, false)
-	s.eliminated = append(s.frozen, false)
+	s.eliminated = append(s.eliminated, false)
 	if s.useSimp {
 		// because numOcc maps literals to counts the new variable will take up
 		// the next two positions for its positive and negative literals
@@ -181,7 +225,7 @@ func (s *Simp) ReleaseVar(p Lit) {
 		s.d.ReleaseVar(p)
 	} else {
 		// don't allow the variable to be reused
-		s.d.AddClause(p)
+		s.d.addClause([]Lit{p})
 	}
 }
 
@@ -233,6 +277,11 @@ func (s *Simp) updateElimHeap(v Var) {
 // AddClause behaves like DPLL.AddClause.  The literals given to AddClause
 // cannot contain eliminated variables.
 func (s *Simp) AddClause(ps ...Lit) bool {
+	s.d.retainClause(ps)
+	return s.addClause(ps...)
+}
+
+func (s *Simp) addClause(ps ...Lit) bool {
 	for i := range ps {
 		if s.IsEliminated(ps[i].Var()) {
 			panic("clause contains eliminated variable")
@@ -245,7 +294,7 @@ func (s *Simp) AddClause(ps ...Lit) bool {
 		return true
 	}
 
-	if !s.d.AddClause(ps...) {
+	if !s.d.addClause(ps) {
 		return false
 	}
 
@@ -266,6 +315,13 @@ func (s *Simp) AddClause(ps ...Lit) bool {
 	return true
 }
 
+// Opt returns the options of the solver underlying s.  Unlike those given to
+// NewSimp, zero fields assigned through Opt are used as given.  The options
+// must not be modified during a search.
+func (s *Simp) Opt() *Opt {
+	return &s.d.Opt
+}
+
 // Interrupt allows a goroutine to interrupt a long running concurrent search.
 func (s *Simp) Interrupt() {
 	s.d.Interrupt()
@@ -276,14 +332,71 @@ func (s *Simp) ClearInterrupt() {
 	s.d.ClearInterrupt()
 }
 
+// SetTerminate behaves like DPLL.SetTerminate.  The function is also polled
+// by Eliminate.
+func (s *Simp) SetTerminate(fn func() bool) {
+	s.d.SetTerminate(fn)
+}
+
+// SetConflictBudget behaves like DPLL.SetConflictBudget.
+func (s *Simp) SetConflictBudget(n int64) {
+	s.d.SetConflictBudget(n)
+}
+
+// SetPropagationBudget behaves like DPLL.SetPropagationBudget.
+func (s *Simp) SetPropagationBudget(n int64) {
+	s.d.SetPropagationBudget(n)
+}
+
+// SetTimeBudget behaves like DPLL.SetTimeBudget.  The budget also limits
+// Eliminate.
+func (s *Simp) SetTimeBudget(dur time.Duration) {
+	s.d.SetTimeBudget(dur)
+}
+
+// SetMemoryBudget behaves like DPLL.SetMemoryBudget.
+func (s *Simp) SetMemoryBudget(n uint64) {
+	s.d.SetMemoryBudget(n)
+}
+
+// Limit behaves like DPLL.Limit.
+func (s *Simp) Limit() Limit {
+	return s.d.Limit()
+}
+
+// Progress behaves like DPLL.Progress.
+func (s *Simp) Progress() float64 {
+	return s.d.Progress()
+}
+
+// BudgetOff behaves like DPLL.BudgetOff.
+func (s *Simp) BudgetOff() {
+	s.d.BudgetOff()
+}
+
 // Okay returns true if s hasn't yet found a contradiction
 func (s *Simp) Okay() bool {
 	return s.d.ok
 }
 
+// Model behaves like DPLL.Model.  Unless NoExtend is set the model includes
+// assignments for eliminated variables.
+func (s *Simp) Model() []LBool {
+	return s.d.Model()
+}
+
+// Conflict behaves like DPLL.Conflict.
+func (s *Simp) Conflict() []Lit {
+	return s.d.Conflict()
+}
+
 // PrintStats prints solver stats after Solve has returned
 func (s *Simp) PrintStats() {
 	s.d.PrintStats()
+	log.Printf("eliminated vars       : %-12d", s.nelimvars)
+	log.Printf("gate definitions      : %-12d   (%d equiv, %d and, %d xor, %d ite)",
+		s.ngates[GateEquiv]+s.ngates[GateAnd]+s.ngates[GateXor]+s.ngates[GateITE],
+		s.ngates[GateEquiv], s.ngates[GateAnd], s.ngates[GateXor], s.ngates[GateITE])
 }
 
 // SolveSimp is like Solve but allows something...
@@ -341,6 +454,9 @@ func (s *Simp) solve(doSimp, turnOffSimp bool) LBool {
 
 	if result.IsTrue() && !s.NoExtend {
 		s.extendModel()
+		if s.VerifyModel {
+			s.d.verifyModel()
+		}
 	}
 
 	if doSimp {
@@ -353,7 +469,8 @@ func (s *Simp) solve(doSimp, turnOffSimp bool) LBool {
 }
 
 func (s *Simp) removeClause(c *Clause) {
-	if s.useSimp {
+	// learnt clauses are never indexed by occurrence
+	if s.useSimp && !c.Learnt {
 		for _, p := range c.Lit {
 			s.numOcc[p]--
 			s.updateElimHeap(p.Var())
@@ -382,7 +499,7 @@ func (s *Simp) Eliminate(turnOffElim bool) bool {
 			goto cleanup
 		}
 
-		if s.d.wasInterrupted() {
+		if s.d.wasInterrupted() || !s.withinSimpBudget() {
 			if s.bwdsubAssigns != len(s.d.trail) {
 				panic("bwdsubAssigns in an inconsistent state when interrupted")
 			}
@@ -402,7 +519,7 @@ func (s *Simp) Eliminate(turnOffElim bool) bool {
 				break
 			}
 
-			if s.d.wasInterrupted() {
+			if s.d.wasInterrupted() || !s.withinSimpBudget() {
 				break
 			}
 
@@ -462,6 +579,8 @@ cleanup:
 	if s.d.Verbosity >= 1 {
 		log.Printf("|  Eliminated clauses:   %12d (%10.2f MB)                         |",
 			len(s.elimClauses), float64(len(s.elimClauses))*float64(4)/float64(1024*1024))
+		log.Printf("|  Gate definitions:     %8d equiv %8d and %8d xor %8d ite   |",
+			s.ngates[GateEquiv], s.ngates[GateAnd], s.ngates[GateXor], s.ngates[GateITE])
 	}
 
 	return s.d.ok
@@ -473,12 +592,13 @@ func (s *Simp) extendModel() {
 
 nextClause:
 	for i := len(s.elimClauses) - 1; i > 0; i -= int(j) {
-		for j = s.elimClauses[i]; j > 1; {
+		// skip over the clause length to the last literal
+		j = s.elimClauses[i]
+		i--
+		for ; j > 1; j, i = j-1, i-1 {
 			if !s.d.ValueLitModel(Lit(s.elimClauses[i])).IsFalse() {
 				continue nextClause
 			}
-			j--
-			i--
 		}
 
 		p = Lit(s.elimClauses[i])
@@ -514,7 +634,7 @@ func (s *Simp) substitute(v Var, p Lit) bool {
 		}
 
 		s.removeClause(c)
-		if !s.AddClause(sc...) {
+		if !s.addClause(sc...) {
 			s.d.ok = false
 			return false
 		}
@@ -548,18 +668,22 @@ func (s *Simp) eliminateVar(v Var) bool {
 		}
 	}
 
+	var g *gate
+	if !s.NoGates {
+		g = s.findGate(v, pos, neg)
+	}
+	pairs := resolventPairs(pos, neg, g)
+
 	var ok bool
 	count := 0
 	clauseSize := 0
 
-	for i := range pos {
-		for j := range neg {
-			ok, clauseSize = s.mergeSize(pos[i], neg[j], v)
-			if ok {
-				count++
-				if count > len(cs)+s.Grow || (s.ClauseLimit != -1 && clauseSize > s.ClauseLimit) {
-					return true
-				}
+	for _, pair := range pairs {
+		ok, clauseSize = s.mergeSize(pair[0], pair[1], v)
+		if ok {
+			count++
+			if count > len(cs)+s.Grow || (s.ClauseLimit != -1 && clauseSize > s.ClauseLimit) {
+				return true
 			}
 		}
 	}
@@ -567,6 +691,12 @@ func (s *Simp) eliminateVar(v Var) bool {
 	s.eliminated[v] = true
 	s.d.SetDecision(v, false)
 	s.nelimvars++
+	if g != nil {
+		s.ngates[g.kind]++
+		if s.d.Verbosity >= 3 {
+			log.Printf("GATE %v", g)
+		}
+	}
 
 	if len(pos) > len(neg) {
 		for i := range neg {
@@ -584,12 +714,10 @@ func (s *Simp) eliminateVar(v Var) bool {
 		s.removeClause(cs[i])
 	}
 
-	for i := range pos {
-		for j := range neg {
-			ok, psResolvent := s.merge(pos[i], neg[j], v)
-			if ok && !s.AddClause(psResolvent...) {
-				return false
-			}
+	for _, pair := range pairs {
+		ok, psResolvent := s.merge(pair[0], pair[1], v)
+		if ok && !s.addClause(psResolvent...) {
+			return false
 		}
 	}
 
@@ -604,6 +732,24 @@ func (s *Simp) eliminateVar(v Var) bool {
 	return s.backwardSubsumptionCheck(false)
 }
 
+// resolventPairs returns the pairs of clauses which must be resolved to
+// eliminate a variable occurring positively in pos and negatively in neg.
+// Without a gate definition every clause in pos is resolved with every clause
+// in neg.  With a definition only gate clauses are resolved with non-gate
+// clauses, as the resolvents of two gate clauses are tautologies and those of
+// two non-gate clauses are implied by the others.
+func resolventPairs(pos, neg []*Clause, g *gate) [][2]*Clause {
+	var pairs [][2]*Clause
+	for _, cp := range pos {
+		for _, cn := range neg {
+			if g == nil || g.contains(cp) != g.contains(cn) {
+				pairs = append(pairs, [2]*Clause{cp, cn})
+			}
+		}
+	}
+	return pairs
+}
+
 func (s *Simp) mkElimClause(p Lit) {
 	s.elimClauses = append(s.elimClauses, uint32(p))
 	s.elimClauses = append(s.elimClauses, 1) // undefined literal
@@ -686,7 +832,7 @@ func (s *Simp) backwardSubsumptionCheck(verbose bool) bool {
 	numDeletedLiterals := 0
 	for s.subQueue.Len() > 0 || s.bwdsubAssigns < len(s.d.trail) {
 		// user interrupt -- empty subsumption queue and return immediately
-		if s.d.wasInterrupted() {
+		if s.d.wasInterrupted() || !s.withinSimpBudget() {
 			s.subQueue.clear()
 			s.bwdsubAssigns = len(s.d.trail)
 			break
@@ -732,6 +878,7 @@ func (s *Simp) backwardSubsumptionCheck(verbose bool) bool {
 				break
 			}
 			if cs[j].Mark == 0 && cs[j] != c && (s.SubsumptionLimit == -1 || cs[j].Len() < s.SubsumptionLimit) {
+				s.nsubcheck++
 				ok, p := c.Subsumes(cs[j])
 				if !ok {
 					continue
@@ -0,0 +1,518 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// A snapshot begins with snapshotMagic, the format version, and the kind of
+// solver saved.  All integers are varint encoded and floating point values
+// are encoded by their IEEE 754 bits.
+const (
+	snapshotMagic   = "dpllsnap"
+	snapshotVersion = 2
+
+	snapshotDPLL = 0
+	snapshotSimp = 1
+)
+
+// WriteSnapshot writes the state of d to w in a versioned binary format.  The
+// snapshot contains the variables with their heuristic state, the top level
+// assignments, the original and learnt clauses, clause groups, statistics and
+// the state of the random number generator.  Options, any connected
+// Propagator, the clauses retained for VerifyModel and the results of the
+// last call to Solve are not included.
+// WriteSnapshot must not be called during a search.
+func (d *DPLL) WriteSnapshot(w io.Writer) error {
+	sw := newSnapshotWriter(w, snapshotDPLL)
+	d.writeSnapshot(sw)
+	return sw.flush()
+}
+
+// ReadSnapshot returns a solver with the state written by WriteSnapshot.  The
+// solver is created with opt, although the state of the random number
+// generator is taken from the snapshot.
+func ReadSnapshot(r io.Reader, opt *Opt) (*DPLL, error) {
+	sr, err := newSnapshotReader(r, snapshotDPLL)
+	if err != nil {
+		return nil, err
+	}
+	d := New(opt)
+	d.readSnapshot(sr, d.NewVar)
+	if sr.err != nil {
+		return nil, sr.err
+	}
+	return d, nil
+}
+
+// WriteSnapshot behaves like DPLL.WriteSnapshot.  The snapshot also contains
+// the frozen and eliminated variables and the clauses needed to extend models
+// to eliminated variables.
+func (s *Simp) WriteSnapshot(w io.Writer) error {
+	sw := newSnapshotWriter(w, snapshotSimp)
+	s.d.writeSnapshot(sw)
+
+	sw.bool(s.useSimp)
+	sw.uint(uint64(s.maxSimpVar))
+	sw.vars(s.frozenVars)
+	for v := 1; v <= s.d.NumVar(); v++ {
+		sw.bool(s.frozen[v])
+		sw.bool(s.eliminated[v])
+	}
+	sw.uint(uint64(len(s.elimClauses)))
+	for _, x := range s.elimClauses {
+		sw.uint(uint64(x))
+	}
+	for _, n := range s.stats() {
+		sw.uint(uint64(*n))
+	}
+	sw.uint(s.inprocessConf)
+	sw.uint(s.inprocessProp)
+
+	return sw.flush()
+}
+
+// ReadSimpSnapshot returns a simplifying solver with the state written by
+// Simp.WriteSnapshot.  The solver is created with opt and simpOpt.
+func ReadSimpSnapshot(r io.Reader, opt *Opt, simpOpt *SimpOpt) (*Simp, error) {
+	sr, err := newSnapshotReader(r, snapshotSimp)
+	if err != nil {
+		return nil, err
+	}
+	s := NewSimp(opt, simpOpt)
+	s.d.readSnapshot(sr, s.NewVar)
+
+	useSimp := sr.bool()
+	s.maxSimpVar = Var(sr.uint())
+	s.frozenVars = sr.vars(s.d.NumVar())
+	for v := 1; v <= s.d.NumVar() && sr.err == nil; v++ {
+		s.frozen[v] = sr.bool()
+		s.eliminated[v] = sr.bool()
+	}
+	n := sr.len()
+	s.elimClauses = make([]uint32, 0, n)
+	for i := 0; i < n && sr.err == nil; i++ {
+		s.elimClauses = append(s.elimClauses, uint32(sr.uint()))
+	}
+	for _, n := range s.stats() {
+		*n = int(sr.uint())
+	}
+	s.inprocessConf = sr.uint()
+	s.inprocessProp = sr.uint()
+	if sr.err != nil {
+		return nil, sr.err
+	}
+
+	if useSimp {
+		s.rebuildOccurs()
+	} else {
+		s.touched = nil
+		s.occurs = nil
+		s.numOcc = nil
+		s.elimHeap = nil
+		s.subQueue = nil
+		s.useSimp = false
+		s.d.removeSat = true
+	}
+	return s, nil
+}
+
+// stats returns the statistics of s saved in snapshots.
+func (s *Simp) stats() []*int {
+	ns := []*int{&s.nmerge, &s.nasymmlit, &s.nelimvars, &s.nsubcheck, &s.ninprocess}
+	for i := range s.ngates {
+		ns = append(ns, &s.ngates[i])
+	}
+	return ns
+}
+
+// stats returns the cumulative statistics of d saved in snapshots.
+// Statistics describing the current clauses are computed as they are added.
+func (d *DPLL) stats() []*uint64 {
+	return []*uint64{
+		&d.nsolves, &d.nstarts, &d.ndecisions, &d.nrandDecisions,
+		&d.npropogations, &d.nconflicts, &d.nmaxLit, &d.ntotLit,
+		&d.nprobeFailed, &d.nprobeImplied, &d.nprobeHBR,
+		&d.nvivified, &d.nvivifiedLit, &d.nexported, &d.nimported,
+		&d.nextPropagated, &d.nextReason, &d.nextClause,
+	}
+}
+
+func (d *DPLL) writeSnapshot(sw *snapshotWriter) {
+	if d.decisionLevel() != 0 {
+		panic("non-root decision level")
+	}
+
+	sw.float(d.randSeed)
+	sw.bool(d.ok)
+	sw.float(d.claIncr)
+	sw.float(d.varIncr)
+	sw.int(int64(d.nsimpAssign))
+	sw.int(d.nsimpProps)
+	sw.uint(uint64(d.probeHead))
+	sw.uint(uint64(d.vivifyHead))
+	for _, n := range d.stats() {
+		sw.uint(*n)
+	}
+
+	n := d.NumVar()
+	sw.uint(uint64(n))
+	for v := 1; v <= n; v++ {
+		sw.float(d.activity[v])
+		sw.bool(d.polarity[v])
+		sw.uint(uint64(d.upolarity[v]))
+		sw.bool(d.decision[v])
+	}
+
+	// released variables keep their final assignment until they are reused
+	sw.lits(d.trail)
+	sw.vars(d.releasedVars)
+	free := make([]Lit, len(d.freeVars))
+	for i, v := range d.freeVars {
+		free[i] = Literal(v, d.Value(v).IsFalse())
+	}
+	sw.lits(free)
+
+	sw.clauses(d.clauses)
+	sw.clauses(d.learnt)
+
+	sw.uint(uint64(len(d.groups)))
+	for _, grp := range d.groups {
+		sw.uint(uint64(grp.act))
+		sw.bool(grp.disabled)
+		sw.bool(grp.deleted)
+	}
+
+	var observed []Var
+	for v, ok := range d.observed {
+		if ok {
+			observed = append(observed, Var(v))
+		}
+	}
+	sw.vars(observed)
+}
+
+// readSnapshot restores the state written by writeSnapshot into the new
+// solver d, creating variables with newVar.
+func (d *DPLL) readSnapshot(sr *snapshotReader, newVar func(upol LBool, dvar bool) Var) {
+	seed := sr.float()
+	if sr.err == nil && !validRandSeed(seed) {
+		sr.fail("invalid random seed")
+		return
+	}
+	d.ok = sr.bool()
+	d.claIncr = sr.float()
+	d.varIncr = sr.float()
+	d.nsimpAssign = int(sr.int())
+	d.nsimpProps = sr.int()
+	d.probeHead = int(sr.uint())
+	d.vivifyHead = int(sr.uint())
+	for _, n := range d.stats() {
+		*n = sr.uint()
+	}
+
+	n := sr.len()
+	for v := 1; v <= n && sr.err == nil; v++ {
+		act := sr.float()
+		pol := sr.bool()
+		upol := LBool(sr.uint())
+		dec := sr.bool()
+		if upol > LUndef {
+			sr.fail("invalid polarity")
+		}
+		newVar(upol, dec)
+		d.activity[v] = act
+		d.polarity[v] = pol
+	}
+	if sr.err != nil {
+		return
+	}
+
+	// variables may draw random initial activities so the generator is
+	// restored after they are created
+	d.randSeed = seed
+
+	for _, p := range sr.lits(n) {
+		if !d.ValueLit(p).IsUndef() {
+			sr.fail("duplicate assignment")
+			return
+		}
+		d.uncheckedEnqueue(p, nil)
+	}
+	d.qhead = len(d.trail)
+	d.releasedVars = sr.vars(n)
+	for _, p := range sr.lits(n) {
+		d.assigns[p.Var()] = LiftBool(!p.IsNeg())
+		d.freeVars = append(d.freeVars, p.Var())
+	}
+
+	for _, learnt := range []bool{false, true} {
+		cs := sr.clauses(n, learnt)
+		for _, c := range cs {
+			d.attachClause(c)
+		}
+		if learnt {
+			d.learnt = cs
+		} else {
+			d.clauses = cs
+		}
+	}
+
+	ngroup := sr.len()
+	for g := 0; g < ngroup && sr.err == nil; g++ {
+		act := sr.lit(n)
+		grp := clauseGroup{act: act, disabled: sr.bool(), deleted: sr.bool()}
+		d.groups = append(d.groups, grp)
+		if !grp.deleted {
+			if d.groupOf == nil {
+				d.groupOf = make(map[Var]Group)
+			}
+			d.groupOf[act.Var()] = Group(g)
+		}
+	}
+
+	for _, v := range sr.vars(n) {
+		d.ObserveVar(v)
+	}
+
+	d.rebuildOrderHeap()
+}
+
+// snapshotWriter encodes snapshots.  The first error encountered is retained
+// and all subsequent writes are ignored.
+type snapshotWriter struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func newSnapshotWriter(w io.Writer, kind int) *snapshotWriter {
+	sw := &snapshotWriter{w: bufio.NewWriter(w)}
+	_, sw.err = sw.w.WriteString(snapshotMagic)
+	sw.uint(snapshotVersion)
+	sw.uint(uint64(kind))
+	return sw
+}
+
+func (sw *snapshotWriter) write(b []byte) {
+	if sw.err == nil {
+		_, sw.err = sw.w.Write(b)
+	}
+}
+
+func (sw *snapshotWriter) uint(x uint64) {
+	sw.write(sw.buf[:binary.PutUvarint(sw.buf[:], x)])
+}
+
+func (sw *snapshotWriter) int(x int64) {
+	sw.write(sw.buf[:binary.PutVarint(sw.buf[:], x)])
+}
+
+func (sw *snapshotWriter) bool(b bool) {
+	if b {
+		sw.uint(1)
+	} else {
+		sw.uint(0)
+	}
+}
+
+func (sw *snapshotWriter) float(x float64) {
+	sw.uint(math.Float64bits(x))
+}
+
+func (sw *snapshotWriter) vars(vs []Var) {
+	sw.uint(uint64(len(vs)))
+	for _, v := range vs {
+		sw.uint(uint64(v))
+	}
+}
+
+func (sw *snapshotWriter) lits(ps []Lit) {
+	sw.uint(uint64(len(ps)))
+	for _, p := range ps {
+		sw.uint(uint64(p))
+	}
+}
+
+// clauses writes the clauses of cs which have not been removed.  The
+// activity and LBD of learnt clauses are included.
+func (sw *snapshotWriter) clauses(cs []*Clause) {
+	var n int
+	for _, c := range cs {
+		if !isRemoved(c) {
+			n++
+		}
+	}
+	sw.uint(uint64(n))
+	for _, c := range cs {
+		if isRemoved(c) {
+			continue
+		}
+		sw.lits(c.Lit)
+		if c.ClauseExtra == nil {
+			sw.bool(false)
+			continue
+		}
+		sw.bool(true)
+		sw.float(c.Activity)
+		sw.uint(uint64(c.LBD))
+		sw.bool(c.Vivified)
+	}
+}
+
+func (sw *snapshotWriter) flush() error {
+	if sw.err != nil {
+		return sw.err
+	}
+	return sw.w.Flush()
+}
+
+// snapshotReader decodes snapshots.  The first error encountered is retained
+// and all subsequent reads return zero values.
+type snapshotReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func newSnapshotReader(r io.Reader, kind int) (*snapshotReader, error) {
+	sr := &snapshotReader{r: bufio.NewReader(r)}
+	magic := make([]byte, len(snapshotMagic))
+	_, sr.err = io.ReadFull(sr.r, magic)
+	if sr.err == nil && string(magic) != snapshotMagic {
+		sr.fail("not a snapshot")
+	}
+	version := sr.uint()
+	if sr.err == nil && version != snapshotVersion {
+		sr.fail(fmt.Sprintf("unsupported version %d", version))
+	}
+	k := sr.uint()
+	if sr.err == nil && k != uint64(kind) {
+		sr.fail(fmt.Sprintf("solver kind %d (expected %d)", k, kind))
+	}
+	if sr.err != nil {
+		return nil, sr.err
+	}
+	return sr, nil
+}
+
+func (sr *snapshotReader) fail(msg string) {
+	if sr.err == nil {
+		sr.err = fmt.Errorf("snapshot: %s", msg)
+	}
+}
+
+func (sr *snapshotReader) uint() uint64 {
+	if sr.err != nil {
+		return 0
+	}
+	x, err := binary.ReadUvarint(sr.r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	sr.err = err
+	return x
+}
+
+func (sr *snapshotReader) int() int64 {
+	if sr.err != nil {
+		return 0
+	}
+	x, err := binary.ReadVarint(sr.r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	sr.err = err
+	return x
+}
+
+func (sr *snapshotReader) bool() bool {
+	switch sr.uint() {
+	case 0:
+		return false
+	case 1:
+		return true
+	default:
+		sr.fail("invalid boolean")
+		return false
+	}
+}
+
+func (sr *snapshotReader) float() float64 {
+	return math.Float64frombits(sr.uint())
+}
+
+// len reads a length, which is limited to guard against corrupt input.
+func (sr *snapshotReader) len() int {
+	n := sr.uint()
+	if n > VarMax {
+		sr.fail("invalid length")
+		return 0
+	}
+	return int(n)
+}
+
+// vars reads variables no greater than max.
+func (sr *snapshotReader) vars(max int) []Var {
+	n := sr.len()
+	var vs []Var
+	for i := 0; i < n && sr.err == nil; i++ {
+		v := sr.uint()
+		if v == 0 || v > uint64(max) {
+			sr.fail("variable out of range")
+			return nil
+		}
+		vs = append(vs, Var(v))
+	}
+	return vs
+}
+
+// lit reads a literal of a variable no greater than max.
+func (sr *snapshotReader) lit(max int) Lit {
+	p := Lit(sr.uint())
+	if sr.err == nil && (p.Var() == 0 || int(p.Var()) > max) {
+		sr.fail("literal out of range")
+	}
+	return p
+}
+
+// lits reads literals of variables no greater than max.
+func (sr *snapshotReader) lits(max int) []Lit {
+	n := sr.len()
+	var ps []Lit
+	for i := 0; i < n && sr.err == nil; i++ {
+		ps = append(ps, sr.lit(max))
+	}
+	return ps
+}
+
+// clauses reads clauses over variables no greater than max.
+func (sr *snapshotReader) clauses(max int, learnt bool) []*Clause {
+	n := sr.len()
+	var cs []*Clause
+	for i := 0; i < n && sr.err == nil; i++ {
+		ps := sr.lits(max)
+		extra := sr.bool()
+		if len(ps) < 2 {
+			sr.fail("short clause")
+		}
+		if sr.err != nil {
+			return nil
+		}
+		c := NewClause(ps, extra, learnt)
+		if c.ClauseExtra != nil {
+			c.Activity = sr.float()
+			c.LBD = uint32(sr.uint())
+			c.Vivified = sr.bool()
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
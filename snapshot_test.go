@@ -0,0 +1,155 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDPLL_WriteSnapshot(t *testing.T) {
+	for seed := int64(1); seed < 10; seed++ {
+		p := randomProblem(seed, 80, 340)
+		d := New(nil)
+		addProblem(d, p)
+		d.NewGroup()
+		d.ReleaseVar(Literal(d.NewVar(LUndef, true), false))
+		d.SetConflictBudget(100)
+		if !d.SolveLimited().IsUndef() {
+			continue
+		}
+
+		var buf bytes.Buffer
+		err := d.WriteSnapshot(&buf)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		snap := buf.Bytes()
+		d1, err := ReadSnapshot(bytes.NewReader(snap), nil)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		d2, err := ReadSnapshot(bytes.NewReader(snap), nil)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+
+		// a restored solver has the state it was restored from
+		buf.Reset()
+		err = d1.WriteSnapshot(&buf)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if !bytes.Equal(buf.Bytes(), snap) {
+			t.Fatalf("seed %d: snapshot changed by restoring", seed)
+		}
+		if d1.NumVar() != d.NumVar() || d1.NumAssign() != d.NumAssign() {
+			t.Errorf("seed %d: %d vars %d assigned (expected %d %d)", seed, d1.NumVar(), d1.NumAssign(), d.NumVar(), d.NumAssign())
+		}
+		if len(d1.learnt) != len(d.learnt) || d1.nconflicts != d.nconflicts {
+			t.Errorf("seed %d: %d learnt %d conflicts (expected %d %d)", seed, len(d1.learnt), d1.nconflicts, len(d.learnt), d.nconflicts)
+		}
+
+		// restored solvers resume identically
+		status := d.Solve()
+		status1 := d1.Solve()
+		status2 := d2.Solve()
+		if status1 != status || status2 != status {
+			t.Errorf("seed %d: status %v %v (expected %v)", seed, status1, status2, status)
+		}
+		if d1.nconflicts != d2.nconflicts {
+			t.Errorf("seed %d: conflicts %d (expected %d)", seed, d2.nconflicts, d1.nconflicts)
+		}
+		if status1 {
+			checkModel(t, "restored", p, d1.Model())
+			m1, m2 := d1.Model(), d2.Model()
+			for v := 1; v <= p.NumVar; v++ {
+				if m1[v] != m2[v] {
+					t.Errorf("seed %d: models differ at %d", seed, v)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestSimp_WriteSnapshot(t *testing.T) {
+	for _, turnOff := range []bool{false, true} {
+		p := randomProblem(3, 60, 200)
+		s := NewSimp(nil, nil)
+		addProblem(s, p)
+		s.SetFrozen(1, true)
+		if !s.Eliminate(turnOff) {
+			t.Fatal("unsatisfiable")
+		}
+		var buf bytes.Buffer
+		err := s.WriteSnapshot(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s2, err := ReadSimpSnapshot(&buf, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s2.useSimp != s.useSimp || len(s2.elimClauses) != len(s.elimClauses) {
+			t.Errorf("turnOff %v: simplification state not restored", turnOff)
+		}
+		var neliminated int
+		for v := 1; v <= p.NumVar; v++ {
+			if s2.IsEliminated(Var(v)) {
+				neliminated++
+			}
+		}
+		if neliminated == 0 || !s2.frozen[1] {
+			t.Errorf("turnOff %v: %d eliminated frozen %v", turnOff, neliminated, s2.frozen[1])
+		}
+		if !s2.Solve() {
+			t.Fatalf("turnOff %v: unsatisfiable", turnOff)
+		}
+		checkModel(t, "restored", p, s2.Model())
+	}
+}
+
+func TestReadSnapshot_invalid(t *testing.T) {
+	d := New(nil)
+	addProblem(d, randomProblem(1, 10, 30))
+	var buf bytes.Buffer
+	err := d.WriteSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap := append([]byte(nil), buf.Bytes()...)
+
+	buf.Reset()
+	d.randSeed = 0
+	err = d.WriteSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	badSeed := buf.Bytes()
+
+	for _, test := range []struct {
+		name string
+		snap []byte
+		err  string
+	}{
+		{"magic", append([]byte("dpllsnaq"), snap[8:]...), "not a snapshot"},
+		{"version", append([]byte("dpllsnap\x01"), snap[9:]...), "unsupported version"},
+		{"truncated", snap[:len(snap)/2], "EOF"},
+		{"seed", badSeed, "invalid random seed"},
+	} {
+		_, err := ReadSnapshot(bytes.NewReader(test.snap), nil)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+
+	_, err = ReadSimpSnapshot(bytes.NewReader(snap), nil, nil)
+	if err == nil {
+		t.Errorf("simp: read solver snapshot")
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"math/rand"
 	"sort"
 	"sync/atomic"
 	"time"
@@ -37,6 +36,18 @@ type Opt struct {
 
 	LearntAdjustConfl int
 	LearntAdjustIncr  float64
+
+	Probe       bool  // Probe for failed literals at restarts
+	ProbeBudget int64 // Propagations allowed in each round of probing
+
+	Vivify       bool  // Vivify learnt and original clauses at restarts
+	VivifyBudget int64 // Propagations allowed in each round of vivification
+
+	ExportLearnt func(ps []Lit, lbd int) // Called with each learnt clause; ps must not be modified or retained
+	ExportMaxLen int                     // Learnt clauses longer than this are not exported (0 for no limit)
+	ImportLearnt func() [][]Lit          // Called at restarts for clauses implied by the problem to add as learnt clauses
+
+	VerifyModel bool // Retain the clauses given to AddClause and panic if a model does not satisfy them (costly)
 }
 
 var optDefault = &Opt{
@@ -54,6 +65,10 @@ var optDefault = &Opt{
 
 	LearntAdjustConfl: 100,
 	LearntAdjustIncr:  1.5,
+
+	ProbeBudget: 100000,
+
+	VivifyBudget: 100000,
 }
 
 func mergeOptDefault(o *Opt) *Opt {
@@ -125,6 +140,34 @@ func mergeOpt(o1, o2 *Opt) *Opt {
 		o.LearntAdjustIncr = o2.LearntAdjustIncr
 	}
 
+	if o2.Probe {
+		o.Probe = o2.Probe
+	}
+	if o2.ProbeBudget != 0 {
+		o.ProbeBudget = o2.ProbeBudget
+	}
+
+	if o2.Vivify {
+		o.Vivify = o2.Vivify
+	}
+	if o2.VivifyBudget != 0 {
+		o.VivifyBudget = o2.VivifyBudget
+	}
+
+	if o2.ExportLearnt != nil {
+		o.ExportLearnt = o2.ExportLearnt
+	}
+	if o2.ExportMaxLen != 0 {
+		o.ExportMaxLen = o2.ExportMaxLen
+	}
+	if o2.ImportLearnt != nil {
+		o.ImportLearnt = o2.ImportLearnt
+	}
+
+	if o2.VerifyModel {
+		o.VerifyModel = true
+	}
+
 	return o
 }
 
@@ -141,13 +184,15 @@ type DPLL struct {
 	addClauseFn      func(p ...Lit) bool
 	removeClauseFn   func(c *Clause)
 	garbageCollectFn func()
+	inprocessFn      func() bool
 
 	// the time at which the solver "started" solving the problem.
 	startTime time.Time
 
 	// outputs to Solve
-	model    []LBool
-	conflict []Lit
+	model          []LBool
+	conflict       []Lit
+	conflictGroups []Group
 
 	// stats
 	nsolves        uint64
@@ -164,6 +209,16 @@ type DPLL struct {
 	ngarbLit       uint64
 	nmaxLit        uint64
 	ntotLit        uint64
+	nprobeFailed   uint64
+	nprobeImplied  uint64
+	nprobeHBR      uint64
+	nvivified      uint64
+	nvivifiedLit   uint64
+	nexported      uint64
+	nimported      uint64
+	nextPropagated uint64
+	nextReason     uint64
+	nextClause     uint64
 
 	// core CDCL structures
 	clauses     []*Clause // provided clauses
@@ -172,6 +227,19 @@ type DPLL struct {
 	trailLim    []int     // seprarating indices for decision levels in trail
 	assumptions []Lit     // set of assumptions provided by the user
 
+	// external propagator and the variables it observes
+	prop         Propagator
+	observed     []bool
+	propRejected bool // the last model was rejected by prop
+
+	// clause groups and the group of each activation variable
+	groups  []clauseGroup
+	groupOf map[Var]Group
+
+	// clauses given to AddClause, retained to verify models
+	retain   bool
+	original [][]Lit
+
 	// containers keyed by variables
 	activity  []float64 // measure of occurance
 	assigns   []LBool   // assignments for each variable
@@ -192,6 +260,8 @@ type DPLL struct {
 	nsimpAssign int     // number of top level assignments since last call to Simplify
 	nsimpProps  int64   // number of propagations that must be made before the next call to Simplify
 	progress    float64 // estimate set by search
+	probeHead   int     // rotating index into the probe roots
+	vivifyHead  int     // rotating index into the original clauses for vivification
 	removeSat   bool    // indicates whether a possibly inefficient scan for satisfied clauses should be done in Simplify
 	varNext     Var     // next variable to be created
 
@@ -200,6 +270,8 @@ type DPLL struct {
 	analyzeStack   []shrinkLit
 	analyzeToClear []Lit
 	addTmp         []Lit
+	lbdSeen        []uint32 // keyed by decision level
+	lbdStamp       uint32
 
 	// limits governing garbage collection
 	maxLearnt         float64
@@ -210,8 +282,16 @@ type DPLL struct {
 	conflictBudget    int64
 	propagationBudget int64
 	asyncInterrupt    uint32
+	terminate         func() bool // polled by withinBudget; see SetTerminate
+	deadline          time.Time // end of the time budget; zero if there is none
+	nclockChecks      uint64    // calls to timeExceeded since the clock was read
+	timedOut          bool
+	memoryBudget      uint64 // heap bytes allowed; zero if there is no limit
+	nextMemCheck      uint64 // conflicts at which the heap is next measured
+	memExceeded       bool
+	nmemReduce        uint64 // learnt clause reductions forced by the memory budget
 
-	r *rand.Rand
+	randSeed float64 // state of the pseudorandom number generator
 }
 
 var _ Solver = (*DPLL)(nil)
@@ -239,6 +319,7 @@ func New(opt *Opt) *DPLL {
 	d.polarity = []bool{true}
 	d.upolarity = []LBool{LUndef}
 	d.decision = []bool{false}
+	d.retain = d.VerifyModel
 
 	d.initRand()
 
@@ -258,16 +339,43 @@ func (d *DPLL) initRand() {
 	if seed == 0 {
 		seed = 0x1234C0DE
 	}
-	src := rand.NewSource(seed)
-	d.r = rand.New(src)
+	d.randSeed = newRandSeed(seed)
+}
+
+// randModulus is the modulus of the pseudorandom number generator.
+const randModulus = 2147483647
+
+// newRandSeed returns a valid generator state derived from seed.  The state
+// must be a whole number strictly between 0 and randModulus.
+func newRandSeed(seed int64) float64 {
+	x := seed % randModulus
+	if x < 0 {
+		x = -x
+	}
+	if x == 0 {
+		x = 91648253
+	}
+	return float64(x)
+}
+
+// validRandSeed returns true if x is a state produced by newRandSeed or
+// randf64.
+func validRandSeed(x float64) bool {
+	return x > 0 && x < randModulus && x == math.Trunc(x)
 }
 
+// randf64 returns a pseudorandom number in [0, 1).  The generator is the
+// multiplicative congruential generator of minisat, whose state is a single
+// float64 that can be saved and copied directly.
 func (d *DPLL) randf64() float64 {
-	return d.r.Float64()
+	d.randSeed *= 1389796
+	q := int64(d.randSeed / randModulus)
+	d.randSeed -= float64(q) * randModulus
+	return d.randSeed / randModulus
 }
 
 func (d *DPLL) randn(n int) int {
-	return d.r.Intn(n)
+	return int(d.randf64() * float64(n))
 }
 
 // NewVar adds a new variable. The parameters specify variable mode.
@@ -308,6 +416,7 @@ func (d *DPLL) ReleaseVar(lit Lit) {
 	if d.Value(lit.Var()).IsUndef() {
 		d.addClauseAlias(lit)
 		d.releasedVars = append(d.releasedVars, lit.Var())
+		d.releaseOriginal(lit)
 	}
 }
 
@@ -342,7 +451,10 @@ func (d *DPLL) isSeen(v Var) bool {
 }
 
 func (d *DPLL) reason(v Var) *Clause {
-	return d.vardata[v].Reason
+	if c := d.vardata[v].Reason; c != lazyReason {
+		return c
+	}
+	return d.externalReason(v)
 }
 
 func (d *DPLL) level(v Var) int {
@@ -467,6 +579,9 @@ func (d *DPLL) uncheckedEnqueue(p Lit, from *Clause) {
 	d.assigns[p.Var()] = LiftBool(!p.IsNeg())
 	d.vardata[p.Var()] = varData{from, d.decisionLevel()}
 	d.trail = append(d.trail, p)
+	if d.prop != nil && d.isObserved(p.Var()) {
+		d.prop.Assign(p)
+	}
 }
 
 func isRemoved(c *Clause) bool {
@@ -477,18 +592,15 @@ func (d *DPLL) locked(c *Clause) bool {
 	if !d.ValueLit(c.Lit[0]).IsTrue() {
 		return false
 	}
-	reason := d.reason(c.Lit[0].Var())
-	if reason == nil {
-		return false
-	}
-
-	// BUG: what does the line below mean?
-	// d.ca.lea(reason(var(c[0]))) == &c;
-	return true
+	// the reason is not materialized if it is lazy because it cannot be c
+	return d.vardata[c.Lit[0].Var()].Reason == c
 }
 
 func (d *DPLL) newDecisionLevel() {
 	d.trailLim = append(d.trailLim, len(d.trail))
+	if d.prop != nil {
+		d.prop.NewDecisionLevel()
+	}
 }
 
 func (d *DPLL) decisionLevel() int {
@@ -517,6 +629,14 @@ func (d *DPLL) ClearInterrupt() {
 	atomic.StoreUint32(&d.asyncInterrupt, 0)
 }
 
+// SetTerminate sets a function polled by the goroutine running the search
+// whenever it checks its budgets.  If fn returns true the solver is
+// interrupted as if by Interrupt.  Unlike Interrupt, fn need not be safe to
+// call from other goroutines.  A nil fn removes the function.
+func (d *DPLL) SetTerminate(fn func() bool) {
+	d.terminate = fn
+}
+
 func (d *DPLL) wasInterrupted() bool {
 	return atomic.LoadUint32(&d.asyncInterrupt) != 0
 }
@@ -524,12 +644,43 @@ func (d *DPLL) wasInterrupted() bool {
 func (d *DPLL) budgetOff() {
 	d.conflictBudget = -1
 	d.propagationBudget = -1
+	d.deadline = time.Time{}
+	d.timedOut = false
+	d.memoryBudget = 0
+	d.memExceeded = false
+}
+
+// SetConflictBudget limits calls to SolveLimited to n more conflicts.  The
+// budget is shared by subsequent calls until it is changed or BudgetOff is
+// called.  Solve ignores budgets.
+func (d *DPLL) SetConflictBudget(n int64) {
+	d.setConflBudget(n)
+}
+
+// SetPropagationBudget limits calls to SolveLimited to n more propagations.
+func (d *DPLL) SetPropagationBudget(n int64) {
+	d.setPropBudget(n)
+}
+
+// BudgetOff removes any conflict, propagation, time and memory budgets.
+func (d *DPLL) BudgetOff() {
+	d.budgetOff()
+}
+
+// pollTerminate interrupts d if the function given to SetTerminate returns
+// true.
+func (d *DPLL) pollTerminate() {
+	if d.terminate != nil && !d.wasInterrupted() && d.terminate() {
+		d.Interrupt()
+	}
 }
 
 func (d *DPLL) withinBudget() bool {
+	d.pollTerminate()
 	return !d.wasInterrupted() &&
 		(d.conflictBudget < 0 || d.nconflicts < uint64(d.conflictBudget)) &&
-		(d.propagationBudget < 0 || d.npropogations < uint64(d.propagationBudget))
+		(d.propagationBudget < 0 || d.npropogations < uint64(d.propagationBudget)) &&
+		!d.timeExceeded() && !d.memExceeded
 }
 
 func (d *DPLL) addClauseAlias(c ...Lit) bool {
@@ -540,7 +691,7 @@ func (d *DPLL) dispatchAddClause(c ...Lit) bool {
 	if d.addClauseFn != nil {
 		return d.addClauseFn(c...)
 	}
-	return d.AddClause(c...)
+	return d.addClause(c)
 }
 
 func (d *DPLL) dispatchRemoveClause(c *Clause) {
@@ -554,6 +705,7 @@ func (d *DPLL) dispatchRemoveClause(c *Clause) {
 // AddClause adds a CNF clause containing the given literals.  The literals in
 // c will be sorted.
 func (d *DPLL) AddClause(c ...Lit) bool {
+	d.retainClause(c)
 	return d.addClause(c)
 }
 
@@ -581,8 +733,7 @@ func (d *DPLL) detachClause(c *Clause, strict bool) {
 
 	// stict or lazy detatching
 	if strict {
-		d.watches.Remove(c.Lit[0].Inverse(), watcher{c, c.Lit[1]})
-		d.watches.Remove(c.Lit[1].Inverse(), watcher{c, c.Lit[0]})
+		d.unwatchClause(c)
 	} else {
 		d.watches.Smudge(c.Lit[0].Inverse())
 		d.watches.Smudge(c.Lit[1].Inverse())
@@ -601,6 +752,19 @@ func (d *DPLL) detachClause(c *Clause, strict bool) {
 	}
 }
 
+// unwatchClause removes the watchers of c without counting c as garbage.
+// The watchers are restored by watchClause.
+func (d *DPLL) unwatchClause(c *Clause) {
+	d.watches.Remove(c.Lit[0].Inverse(), watcher{c, c.Lit[1]})
+	d.watches.Remove(c.Lit[1].Inverse(), watcher{c, c.Lit[0]})
+}
+
+// watchClause adds the watchers of c without counting c as a new clause.
+func (d *DPLL) watchClause(c *Clause) {
+	d.watches.Push(c.Lit[0].Inverse(), watcher{c, c.Lit[1]})
+	d.watches.Push(c.Lit[1].Inverse(), watcher{c, c.Lit[0]})
+}
+
 func (d *DPLL) checkGarbage() {
 	d.checkGarbageFrac(d.GarbageFrac, false)
 }
@@ -697,6 +861,9 @@ func (d *DPLL) removeSatisfied(ptr *[]*Clause) {
 	var i, j int
 	for ; i < len(cs); i++ {
 		c := cs[i]
+		if isRemoved(c) {
+			continue
+		}
 		if d.satisfied(c) {
 			d.dispatchRemoveClause(c)
 		} else {
@@ -726,6 +893,10 @@ func (d *DPLL) reduceDB() {
 	var i, j int
 	for ; i < len(d.learnt); i++ {
 		c := d.learnt[i]
+		if isRemoved(c) {
+			// removed by inprocessing and waiting for garbage collection
+			continue
+		}
 		// don't delete binary or locked clauses.
 		if c.Len() > 2 && !d.locked(c) && (i < len(d.learnt)/2 || c.Activity < lowerLimit) {
 			d.dispatchRemoveClause(c)
@@ -829,7 +1000,8 @@ func (d *DPLL) propagate() *Clause {
 	}
 
 	d.npropogations += uint64(numprops)
-	d.nsimpProps += int64(numprops)
+	// Simplify counts down the propagations until it does any work
+	d.nsimpProps -= int64(numprops)
 
 	return conflict
 }
@@ -915,6 +1087,8 @@ func (d *DPLL) solve() LBool {
 
 	d.model = nil
 	d.conflict = nil
+	d.conflictGroups = nil
+	d.assumptions = d.groupAssumptions(d.assumptions)
 
 	defer d.checkGarbageFrac(0, true)
 
@@ -947,6 +1121,12 @@ func (d *DPLL) solve() LBool {
 		if !d.withinBudget() {
 			break
 		}
+		if status.IsUndef() && !d.importLearnt() {
+			status = LFalse
+		}
+		if status.IsUndef() && !d.inprocess() {
+			status = LFalse
+		}
 	}
 
 	if d.Verbosity >= 1 {
@@ -956,8 +1136,13 @@ func (d *DPLL) solve() LBool {
 	if status.IsTrue() {
 		d.model = make([]LBool, d.NumVar()+1)
 		copy(d.model, d.assigns)
+		if d.VerifyModel {
+			d.verifyModel()
+		}
 	} else if status.IsFalse() && len(d.conflict) == 0 {
 		d.ok = false
+	} else if status.IsFalse() && len(d.groups) > 0 {
+		d.conflict, d.conflictGroups = d.splitGroupConflict(d.conflict)
 	}
 
 	d.cancelUntil(0)
@@ -980,7 +1165,12 @@ func (d *DPLL) search(maxconflict int) LBool {
 	d.nstarts++
 
 	for {
-		conflict := d.propagate()
+		var conflict *Clause
+		if d.prop != nil {
+			conflict = d.propagateExternal()
+		} else {
+			conflict = d.propagate()
+		}
 		if conflict != nil {
 			d.nconflicts++
 			numconflict++
@@ -991,9 +1181,12 @@ func (d *DPLL) search(maxconflict int) LBool {
 			d.cancelUntil(btlevel)
 
 			if len(learnt) == 1 {
+				d.exportLearnt(learnt, 1)
 				d.uncheckedEnqueue(learnt[0], nil)
 			} else {
 				c := d.newClause(learnt, true)
+				c.LBD = d.computeLBD(learnt)
+				d.exportLearnt(c.Lit, int(c.LBD))
 				d.learnt = append(d.learnt, c)
 				d.attachClause(c)
 				d.claBumpActivity(c)
@@ -1023,12 +1216,16 @@ func (d *DPLL) search(maxconflict int) LBool {
 				}
 			}
 		} else { // no conflict; c == nil
-			if (maxconflict >= 0 && numconflict >= maxconflict) || !d.withinBudget() {
-				// too many conflicts
+			if !d.withinBudget() {
 				d.progress = d.progressEstimate()
 				d.cancelUntil(0)
 				return LUndef
 			}
+			if maxconflict >= 0 && numconflict >= maxconflict {
+				// too many conflicts; restart
+				d.cancelUntil(0)
+				return LUndef
+			}
 
 			// simplify the set of problem clauses
 			if d.decisionLevel() == 0 && !d.Simplify() {
@@ -1036,6 +1233,9 @@ func (d *DPLL) search(maxconflict int) LBool {
 			}
 
 			// reduce the set of learnt clauses
+			if d.memoryBudget > 0 && d.nconflicts >= d.nextMemCheck {
+				d.checkMemory()
+			}
 			if float64(len(d.learnt)-d.NumAssign()) >= d.maxLearnt {
 				d.reduceDB()
 			}
@@ -1048,7 +1248,7 @@ func (d *DPLL) search(maxconflict int) LBool {
 					// dummy decision level
 					d.newDecisionLevel()
 				} else if d.ValueLit(p).IsFalse() {
-					d.conflict = d.analyzeFinal(p).slice()
+					d.conflict = d.analyzeFinal(p.Inverse()).slice()
 					return LFalse
 				} else {
 					next = p
@@ -1061,6 +1261,9 @@ func (d *DPLL) search(maxconflict int) LBool {
 				next = d.pickBranchLit()
 				if next.IsUndef() {
 					// model found
+					if d.prop != nil && !d.checkExternalModel() {
+						continue
+					}
 					return LTrue
 				}
 			}
@@ -1071,6 +1274,43 @@ func (d *DPLL) search(maxconflict int) LBool {
 	}
 }
 
+// computeLBD returns the number of distinct decision levels of the literals
+// in ps, the literal block distance.
+func (d *DPLL) computeLBD(ps []Lit) uint32 {
+	d.lbdStamp++
+	var n uint32
+	for _, p := range ps {
+		lvl := d.level(p.Var())
+		for lvl >= len(d.lbdSeen) {
+			d.lbdSeen = append(d.lbdSeen, 0)
+		}
+		if d.lbdSeen[lvl] != d.lbdStamp {
+			d.lbdSeen[lvl] = d.lbdStamp
+			n++
+		}
+	}
+	return n
+}
+
+// inprocess runs the enabled inprocessing techniques at a restart.  inprocess
+// returns false if the clause set was found to be unsatisfiable.
+func (d *DPLL) inprocess() bool {
+	if d.Probe && !d.probe() {
+		return false
+	}
+	if d.Vivify && !d.vivify() {
+		return false
+	}
+	return d.dispatchInprocess()
+}
+
+func (d *DPLL) dispatchInprocess() bool {
+	if d.inprocessFn != nil {
+		return d.inprocessFn()
+	}
+	return true
+}
+
 func (d *DPLL) progressEstimate() float64 {
 	progress := 0.0
 	F := 1.0 / float64(d.NumVar())
@@ -1100,6 +1340,21 @@ func (d *DPLL) PrintStats() {
 	log.Printf("decisions             : %-12d   (%.0f / sec) (%4.2f %% random)", d.ndecisions, float64(d.ndecisions)/runsec, float64(d.nrandDecisions)*100.0)
 	log.Printf("propagations          : %-12d   (%.0f / sec)", d.npropogations, float64(d.npropogations)/runsec)
 	log.Printf("conflict literals     : %-12d   (%4.2f %% deleted)", d.ntotLit, float64(d.nmaxLit-d.ntotLit)*100.0/float64(d.nmaxLit))
+	if d.Probe {
+		log.Printf("failed literals       : %-12d   (%d implied, %d hyper-binary)", d.nprobeFailed, d.nprobeImplied, d.nprobeHBR)
+	}
+	if d.Vivify {
+		log.Printf("vivified clauses      : %-12d   (%d literals removed)", d.nvivified, d.nvivifiedLit)
+	}
+	if d.prop != nil {
+		log.Printf("external propagations : %-12d   (%d reasons, %d clauses)", d.nextPropagated, d.nextReason, d.nextClause)
+	}
+	if d.ExportLearnt != nil || d.ImportLearnt != nil {
+		log.Printf("shared clauses        : %-12d   (%d exported, %d imported)", d.nexported+d.nimported, d.nexported, d.nimported)
+	}
+	if d.nmemReduce != 0 {
+		log.Printf("memory reductions     : %d", d.nmemReduce)
+	}
 	if memused != 0 {
 		log.Printf("memory used           : %.2f MB", memused)
 	}
@@ -1124,29 +1379,34 @@ func (d *DPLL) Okay() bool {
 // true.  Otherwise Implies returns false if the assumptions result in a
 // contradiction.
 func (d *DPLL) Implies(assumps []Lit) (assign []Lit, ok bool) {
-	d.trailLim = append(d.trailLim, len(d.trail))
+	before, ok := d.assume(assumps)
+	if ok {
+		for _, p := range d.trail[before:] {
+			assign = append(assign, p)
+		}
+	}
+
+	d.cancelUntil(0)
+	return assign, ok
+}
+
+// assume opens a new decision level, assigns assumps and propagates them.
+// The returned index into the trail is the position of the first assignment
+// implied by assumps.  If the assumptions result in a contradiction assume
+// returns false.  The caller is responsible for calling cancelUntil(0).
+func (d *DPLL) assume(assumps []Lit) (before int, ok bool) {
+	d.newDecisionLevel()
 	for _, a := range assumps {
 		if d.ValueLit(a).IsFalse() {
-			d.cancelUntil(0)
-			return nil, false
+			return len(d.trail), false
 		}
 		if d.ValueLit(a).IsUndef() {
 			d.uncheckedEnqueue(a, nil)
 		}
 	}
 
-	before := len(d.trail)
-	ok = true
-	if d.propagate() != nil {
-		ok = false
-	} else {
-		for _, p := range d.trail[before:] {
-			assign = append(assign, p)
-		}
-	}
-
-	d.cancelUntil(0)
-	return assign, ok
+	before = len(d.trail)
+	return before, d.propagate() == nil
 }
 
 // Model returns assignments found in the last call to Solve.  If Solve could
@@ -1155,9 +1415,13 @@ func (d *DPLL) Model() []LBool {
 	return d.model
 }
 
-// Conflict returns the final, non-empty clause expressed in assumptions if
-// Solve could not find a model.  If the last call to Solve found a model then
-// Conflict returns nil.
+// Conflict returns the final clause expressed in assumptions if Solve could
+// not find a model.  The clause contains the negation of each assumption used
+// to refute the problem, as in minisat, so it is implied by the clauses of d.
+// Clause groups used to refute the problem are reported by ConflictGroups
+// instead, and assumptions which were not needed are left out, so the clause
+// is empty if no assumption was needed.  If the last call to Solve found a
+// model then Conflict returns nil.
 func (d *DPLL) Conflict() []Lit {
 	return d.conflict
 }
@@ -1238,7 +1502,7 @@ func (d *DPLL) analyzeFinal(p Lit) litSet {
 		if d.isSeen(v) {
 			c := d.reason(v)
 			if c == nil {
-				if d.level(v) > 0 {
+				if d.level(v) == 0 {
 					panic(fmt.Sprintf("var level: %d", d.level(v)))
 				}
 				conflict.insert(d.trail[i].Inverse())
@@ -1439,8 +1703,7 @@ func (d *DPLL) attachClause(c *Clause) {
 		panic("small clause")
 	}
 
-	d.watches.Push(c.Lit[0].Inverse(), watcher{c, c.Lit[1]})
-	d.watches.Push(c.Lit[1].Inverse(), watcher{c, c.Lit[0]})
+	d.watchClause(c)
 
 	if c.Learnt {
 		d.nlearnt++
@@ -1469,6 +1732,9 @@ func (d *DPLL) cancelUntil(level int) {
 	d.qhead = d.trailLim[level]
 	d.trail = d.trail[:d.trailLim[level]]
 	d.trailLim = d.trailLim[:level]
+	if d.prop != nil {
+		d.prop.Backtrack(level)
+	}
 }
 
 // CCMinMode controls conflict clause minimization.
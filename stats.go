@@ -0,0 +1,93 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+// Stats are the cumulative statistics of a DPLL solver, which PrintStats
+// logs.  Counters for features which are not enabled are zero.
+type Stats struct {
+	Solves          uint64 `json:"solves"`
+	Restarts        uint64 `json:"restarts"`
+	Conflicts       uint64 `json:"conflicts"`
+	Decisions       uint64 `json:"decisions"`
+	RandDecisions   uint64 `json:"rand_decisions"`
+	Propagations    uint64 `json:"propagations"`
+	ConflictLits    uint64 `json:"conflict_lits"`     // literals in learnt clauses after minimization
+	MaxConflictLits uint64 `json:"max_conflict_lits"` // literals in learnt clauses before minimization
+
+	ProbeFailed    uint64  `json:"probe_failed"`
+	ProbeImplied   uint64  `json:"probe_implied"`
+	ProbeHBR       uint64  `json:"probe_hbr"`
+	Vivified       uint64  `json:"vivified"`
+	VivifiedLits   uint64  `json:"vivified_lits"`
+	Exported       uint64  `json:"exported"`
+	Imported       uint64  `json:"imported"`
+	ExtPropagated  uint64  `json:"ext_propagated"`
+	ExtReasons     uint64  `json:"ext_reasons"`
+	ExtClauses     uint64  `json:"ext_clauses"`
+	MemReductions  uint64  `json:"memory_reductions"` // reductions of learnt clauses forced by the memory budget
+	Progress       float64 `json:"progress"`          // estimate of the search space explored, see Progress
+	RuntimeSeconds float64 `json:"runtime_seconds"`   // time since the last call to Solve began
+}
+
+// Stats returns the statistics of d.
+func (d *DPLL) Stats() Stats {
+	st := Stats{
+		Solves:          d.nsolves,
+		Restarts:        d.nstarts,
+		Conflicts:       d.nconflicts,
+		Decisions:       d.ndecisions,
+		RandDecisions:   d.nrandDecisions,
+		Propagations:    d.npropogations,
+		ConflictLits:    d.ntotLit,
+		MaxConflictLits: d.nmaxLit,
+		ProbeFailed:     d.nprobeFailed,
+		ProbeImplied:    d.nprobeImplied,
+		ProbeHBR:        d.nprobeHBR,
+		Vivified:        d.nvivified,
+		VivifiedLits:    d.nvivifiedLit,
+		Exported:        d.nexported,
+		Imported:        d.nimported,
+		ExtPropagated:   d.nextPropagated,
+		ExtReasons:      d.nextReason,
+		ExtClauses:      d.nextClause,
+		MemReductions:   d.nmemReduce,
+		Progress:        d.progress,
+	}
+	if !d.startTime.IsZero() {
+		st.RuntimeSeconds = d.runtime()
+	}
+	return st
+}
+
+// SimpStats are the cumulative statistics of a Simp solver.
+type SimpStats struct {
+	Stats
+	EliminatedVars   int `json:"eliminated_vars"`
+	Merges           int `json:"merges"`
+	AsymmLits        int `json:"asymm_lits"`
+	SubsumptionTests int `json:"subsumption_tests"`
+	Inprocess        int `json:"inprocess"` // rounds of inprocessing
+	EquivGates       int `json:"equiv_gates"`
+	AndGates         int `json:"and_gates"`
+	XorGates         int `json:"xor_gates"`
+	ITEGates         int `json:"ite_gates"`
+}
+
+// Stats returns the statistics of s.
+func (s *Simp) Stats() SimpStats {
+	return SimpStats{
+		Stats:            s.d.Stats(),
+		EliminatedVars:   s.nelimvars,
+		Merges:           s.nmerge,
+		AsymmLits:        s.nasymmlit,
+		SubsumptionTests: s.nsubcheck,
+		Inprocess:        s.ninprocess,
+		EquivGates:       s.ngates[GateEquiv],
+		AndGates:         s.ngates[GateAnd],
+		XorGates:         s.ngates[GateXor],
+		ITEGates:         s.ngates[GateITE],
+	}
+}
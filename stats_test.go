@@ -0,0 +1,32 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import "testing"
+
+func TestSimp_Stats(t *testing.T) {
+	s := NewSimp(nil, nil)
+	_, err := DecodeFile(s, "testdata/factoring_5_7.cnf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := s.Stats(); st.Solves != 0 || st.Conflicts != 0 || st.RuntimeSeconds != 0 {
+		t.Errorf("stats before solving: %+v", st)
+	}
+	if !s.SolveSimp(nil, true, true) {
+		t.Fatalf("unsat")
+	}
+	st := s.Stats()
+	if st.Solves != 1 || st.Decisions == 0 || st.Conflicts != s.d.nconflicts || st.Decisions != s.d.ndecisions {
+		t.Errorf("stats: %+v", st.Stats)
+	}
+	if st.EliminatedVars == 0 || st.EliminatedVars != s.nelimvars {
+		t.Errorf("eliminated vars: %d", st.EliminatedVars)
+	}
+	if st.RuntimeSeconds <= 0 {
+		t.Errorf("runtime: %v", st.RuntimeSeconds)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"fmt"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+// Verify returns an error naming the first clause of problem which is not
+// satisfied by model.  The model is indexed by variable, as returned by
+// Model, and a clause is satisfied only if the model makes one of its
+// literals true.  Variables missing from the model are unassigned.
+//
+//		p, err := dimacs.DecodeFile("problem.cnf")
+//		// ...
+//		if solver.Solve() {
+//			err = dpll.Verify(p, solver.Model())
+//		}
+func Verify(problem *dimacs.Problem, model []LBool) error {
+	for i, c := range problem.Clauses {
+		sat := false
+		for _, x := range c {
+			v := x.Var()
+			if v < len(model) && model[v].Xor(x.Neg()).IsTrue() {
+				sat = true
+				break
+			}
+		}
+		if !sat {
+			return fmt.Errorf("clause %d not satisfied: %v", i+1, c)
+		}
+	}
+	return nil
+}
+
+// retainClause records a copy of ps if models are to be verified.
+func (d *DPLL) retainClause(ps []Lit) {
+	if d.retain {
+		d.original = append(d.original, append([]Lit(nil), ps...))
+	}
+}
+
+// releaseOriginal removes lit.Var() from the retained clauses after it has
+// been released with lit true, because the variable may be reused.  Clauses
+// containing lit are satisfied and the inverse of lit is removed from the
+// others.
+func (d *DPLL) releaseOriginal(lit Lit) {
+	if !d.retain {
+		return
+	}
+	var j int
+	for _, ps := range d.original {
+		if containsLitSlice(ps, lit) {
+			continue
+		}
+		var k int
+		for _, p := range ps {
+			if p != lit.Inverse() {
+				ps[k] = p
+				k++
+			}
+		}
+		d.original[j] = ps[:k]
+		j++
+	}
+	d.original = d.original[:j]
+}
+
+// verifyModel panics if d.model does not satisfy the retained clauses.
+func (d *DPLL) verifyModel() {
+	for i, ps := range d.original {
+		sat := false
+		for _, p := range ps {
+			if d.modelValue(p).IsTrue() {
+				sat = true
+				break
+			}
+		}
+		if !sat {
+			panic(fmt.Sprintf("model does not satisfy clause %d: %v", i+1, ps))
+		}
+	}
+}
+
+func (d *DPLL) modelValue(p Lit) LBool {
+	if int(p.Var()) >= len(d.model) {
+		return LUndef
+	}
+	return d.model[p.Var()].Xor(p.IsNeg())
+}
+
+func containsLitSlice(ps []Lit, p Lit) bool {
+	for _, q := range ps {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmatsuo/dpll/dimacs"
+)
+
+func TestVerify(t *testing.T) {
+	p := &dimacs.Problem{
+		NumVar:  3,
+		Clauses: [][]dimacs.Lit{{1, -2}, {2, 3}, {-1, -3}},
+	}
+	err := Verify(p, []LBool{LUndef, LTrue, LTrue, LFalse})
+	if err != nil {
+		t.Errorf("verify: %v", err)
+	}
+	err = Verify(p, []LBool{LUndef, LTrue, LFalse, LTrue})
+	if err == nil || !strings.Contains(err.Error(), "clause 3") {
+		t.Errorf("verify: %v", err)
+	}
+	// variables missing from the model are unassigned
+	err = Verify(p, []LBool{LUndef, LTrue, LTrue})
+	if err == nil || !strings.Contains(err.Error(), "clause 3") {
+		t.Errorf("verify: %v", err)
+	}
+}
+
+func TestVerify_solve(t *testing.T) {
+	p, err := dimacs.DecodeFile("testdata/factoring_3_5.cnf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []Solver{
+		New(&Opt{VerifyModel: true}),
+		NewSimp(&Opt{VerifyModel: true}, nil),
+		NewSimp(nil, &SimpOpt{VerifyModel: true, Asymm: true}),
+	} {
+		_, err := DecodeFile(s, "testdata/factoring_3_5.cnf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var model []LBool
+		switch s := s.(type) {
+		case *DPLL:
+			if !s.Solve() {
+				t.Fatalf("%T: unsat", s)
+			}
+			model = s.Model()
+		case *Simp:
+			if !s.SolveSimp(nil, true, false) {
+				t.Fatalf("%T: unsat", s)
+			}
+			if s.nelimvars == 0 {
+				t.Errorf("no variables eliminated")
+			}
+			model = s.Model()
+		}
+		err = Verify(p, model)
+		if err != nil {
+			t.Errorf("%T: %v", s, err)
+		}
+	}
+}
+
+func TestDPLL_VerifyModel(t *testing.T) {
+	d := New(&Opt{VerifyModel: true})
+	for i := 0; i < 3; i++ {
+		d.NewVar(LUndef, true)
+	}
+	d.AddClause(Literal(1, false), Literal(2, false))
+	d.AddClause(Literal(2, true), Literal(3, false))
+
+	// a deleted group releases its activation variable, which is reused
+	g := d.NewGroup()
+	d.AddClauseToGroup(g, Literal(1, true))
+	d.DeleteGroup(g)
+	if !d.Solve() {
+		t.Fatalf("unsat")
+	}
+	if v := d.NewVar(LUndef, true); v != 4 {
+		t.Fatalf("variable %d not reused", v)
+	}
+	d.AddClause(Literal(4, true), Literal(1, true))
+	if !d.Solve() {
+		t.Fatalf("unsat")
+	}
+	if len(d.original) != 3 {
+		t.Errorf("retained %d clauses: %v", len(d.original), d.original)
+	}
+
+	// a wrong model is detected
+	d.model[2] = LFalse
+	d.model[1] = LFalse
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "clause 1") {
+			t.Errorf("recovered %q", msg)
+		}
+	}()
+	d.verifyModel()
+}
@@ -0,0 +1,152 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import (
+	"log"
+	"sort"
+)
+
+// vivify performs a round of clause vivification.  Learnt clauses are
+// vivified in order of increasing LBD and decreasing activity, each at most
+// once.  Original clauses are vivified in a rotating order.  Each group is
+// allowed half of VivifyBudget propagations.  vivify returns false if the
+// clause set was found to be unsatisfiable.
+func (d *DPLL) vivify() bool {
+	if d.decisionLevel() != 0 {
+		panic("non-root decision level")
+	}
+	if !d.ok || d.propagate() != nil {
+		d.ok = false
+		return false
+	}
+
+	nvivified := d.nvivified
+	nlit := d.nvivifiedLit
+
+	var learnt []*Clause
+	for _, c := range d.learnt {
+		if c.Len() > 2 && !c.Vivified && !isRemoved(c) {
+			learnt = append(learnt, c)
+		}
+	}
+	sort.Sort(clausesByLBD(learnt))
+
+	limit := d.npropogations + uint64(d.VivifyBudget/2)
+	for i := 0; i < len(learnt) && d.npropogations < limit && d.withinBudget(); i++ {
+		learnt[i].Vivified = true
+		if !d.vivifyClause(learnt[i]) {
+			d.ok = false
+			return false
+		}
+	}
+
+	// d.clauses may change length as clauses are vivified.  The clauses are
+	// copied so each is considered once.
+	clauses := make([]*Clause, len(d.clauses))
+	copy(clauses, d.clauses)
+	limit = d.npropogations + uint64(d.VivifyBudget/2)
+	for i := 0; i < len(clauses) && d.npropogations < limit && d.withinBudget(); i++ {
+		c := clauses[d.vivifyHead%len(clauses)]
+		d.vivifyHead++
+		if c.Len() <= 2 {
+			continue
+		}
+		if !d.vivifyClause(c) {
+			d.ok = false
+			return false
+		}
+	}
+
+	if d.Verbosity >= 2 {
+		log.Printf("|  Vivification: %8d clauses %8d literals removed                     |",
+			d.nvivified-nvivified, d.nvivifiedLit-nlit)
+	}
+
+	return true
+}
+
+// vivifyClause attempts to shorten c by assigning the negation of its
+// literals one at a time.  Literals made false by the assignment of earlier
+// literals are removed.  If a literal is made true, or propagating its
+// negation results in a conflict, the remaining literals are removed.  A
+// shortened clause replaces c.  vivifyClause returns false if the clause set
+// was found to be unsatisfiable.
+func (d *DPLL) vivifyClause(c *Clause) bool {
+	if isRemoved(c) || d.locked(c) || d.satisfied(c) {
+		return true
+	}
+
+	// c must not propagate its own literals
+	d.unwatchClause(c)
+	d.newDecisionLevel()
+	var ps []Lit
+	for _, p := range c.Lit {
+		val := d.ValueLit(p)
+		if val.IsFalse() {
+			continue
+		}
+		ps = append(ps, p)
+		if val.IsTrue() {
+			break
+		}
+		d.uncheckedEnqueue(p.Inverse(), nil)
+		if d.propagate() != nil {
+			break
+		}
+	}
+	d.cancelUntil(0)
+	d.watchClause(c)
+
+	if len(ps) == c.Len() {
+		return true
+	}
+
+	d.nvivified++
+	d.nvivifiedLit += uint64(c.Len() - len(ps))
+
+	switch {
+	case len(ps) == 0:
+		return false
+	case c.Learnt && len(ps) > 1:
+		c2 := d.newClause(ps, true)
+		c2.Activity = c.Activity
+		c2.LBD = c.LBD
+		if c2.LBD > uint32(len(ps)) {
+			c2.LBD = uint32(len(ps))
+		}
+		c2.Vivified = true
+		d.learnt = append(d.learnt, c2)
+		d.attachClause(c2)
+		d.removeClause(c)
+		return true
+	case c.Learnt:
+		d.removeClause(c)
+	default:
+		d.dispatchRemoveClause(c)
+	}
+
+	// the addClause path handles units as well as dispatching to any
+	// subclass which tracks original clauses.
+	return d.dispatchAddClause(ps...)
+}
+
+type clausesByLBD []*Clause
+
+func (cs clausesByLBD) Len() int {
+	return len(cs)
+}
+
+func (cs clausesByLBD) Less(i, j int) bool {
+	if cs[i].LBD != cs[j].LBD {
+		return cs[i].LBD < cs[j].LBD
+	}
+	return cs[i].Activity > cs[j].Activity
+}
+
+func (cs clausesByLBD) Swap(i, j int) {
+	cs[i], cs[j] = cs[j], cs[i]
+}
@@ -0,0 +1,146 @@
+// Copyright 2016 Bryan Matsuo
+//
+// Use of this software is governed by the MIT license.  A copy of the license
+// agreement can be found in the LICENSE file distributed with this software.
+
+package dpll
+
+import "testing"
+
+func TestDPLL_vivifyClause(t *testing.T) {
+	d := New(&Opt{Vivify: true})
+	for i := 0; i < 4; i++ {
+		d.NewVar(LUndef, true)
+	}
+	a, b, c, e := LiteralInt(1), LiteralInt(2), LiteralInt(3), LiteralInt(4)
+
+	// ~a -> b so (a | b | c | e) can be shortened to (a | b)
+	d.AddClause(a, b)
+	d.AddClause(a, b, c, e)
+	long := d.clauses[1]
+
+	if !d.vivifyClause(long) {
+		t.Fatalf("unsatisfiable")
+	}
+	if !isRemoved(long) {
+		t.Errorf("clause not removed: %v", long.Lit)
+	}
+	if d.nvivified != 1 || d.nvivifiedLit != 2 {
+		t.Errorf("vivified: %d clauses %d literals", d.nvivified, d.nvivifiedLit)
+	}
+	if d.NumClause() != 2 {
+		t.Errorf("clauses: %d (!= 2)", d.NumClause())
+	}
+	short := d.clauses[len(d.clauses)-1]
+	if short.Len() != 2 {
+		t.Errorf("vivified clause: %v", short.Lit)
+	}
+}
+
+func TestDPLL_vivifyClause_unchanged(t *testing.T) {
+	d := New(&Opt{Vivify: true})
+	for i := 0; i < 3; i++ {
+		d.NewVar(LUndef, true)
+	}
+	d.AddClause(LiteralInt(1), LiteralInt(2), LiteralInt(3))
+	c := d.clauses[0]
+
+	if !d.vivifyClause(c) {
+		t.Fatalf("unsatisfiable")
+	}
+	if isRemoved(c) || d.nvivified != 0 {
+		t.Errorf("clause vivified: %v", c.Lit)
+	}
+	if d.NumClause() != 1 || d.nclauseLit != 3 || d.ngarbLit != 0 {
+		t.Errorf("clauses: %d literals: %d garbage: %d", d.NumClause(), d.nclauseLit, d.ngarbLit)
+	}
+	if len(d.watches.Lookup(LiteralInt(-1))) != 1 || len(d.watches.Lookup(LiteralInt(-2))) != 1 {
+		t.Errorf("watchers not restored")
+	}
+}
+
+func TestDPLL_vivifyClause_learnt(t *testing.T) {
+	d := New(&Opt{Vivify: true})
+	for i := 0; i < 4; i++ {
+		d.NewVar(LUndef, true)
+	}
+	a, b, c, e := LiteralInt(1), LiteralInt(2), LiteralInt(3), LiteralInt(4)
+
+	// ~a & ~b is a conflict so (a | b | c | e) can be shortened to (a | b)
+	d.AddClause(a, b, c)
+	d.AddClause(a, b, c.Inverse())
+	learnt := d.newClause([]Lit{a, b, c, e}, true)
+	learnt.Activity = 3
+	d.learnt = append(d.learnt, learnt)
+	d.attachClause(learnt)
+
+	if !d.vivifyClause(learnt) {
+		t.Fatalf("unsatisfiable")
+	}
+	if !isRemoved(learnt) {
+		t.Errorf("clause not removed: %v", learnt.Lit)
+	}
+	if d.NumLearn() != 1 {
+		t.Fatalf("learnt: %d (!= 1)", d.NumLearn())
+	}
+	short := d.learnt[len(d.learnt)-1]
+	if short.Len() != 2 || short.Activity != 3 {
+		t.Errorf("vivified clause: %v (activity %g)", short.Lit, short.Activity)
+	}
+}
+
+func TestDPLL_Solve_vivify(t *testing.T) {
+	tests := []struct {
+		path string
+		sat  bool
+	}{
+		{"testdata/factoring_2_3.cnf", true},
+		{"testdata/factoring_2_3_UNSAT.cnf", false},
+		{"testdata/factoring_3_5.cnf", true},
+		{"testdata/factoring_3_5_UNSAT.cnf", false},
+		{"testdata/factoring_5_7.cnf", true},
+	}
+	for _, test := range tests {
+		for _, simp := range []bool{false, true} {
+			opt := &Opt{Vivify: true, Probe: true, RestartFirst: 10}
+			var s Solver = New(opt)
+			if simp {
+				s = NewSimp(opt, nil)
+			}
+			_, err := DecodeFile(s, test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sat := s.Solve()
+			if sat != test.sat {
+				t.Errorf("%s (simp %v): sat %v (!= %v)", test.path, simp, sat, test.sat)
+			}
+		}
+	}
+}
+
+func TestDPLL_Solve_vivify_random(t *testing.T) {
+	for seed := int64(1); seed <= 8; seed++ {
+		p := randomProblem(seed, 120, 510)
+
+		d := New(nil)
+		addProblem(d, p)
+		sat := d.Solve()
+
+		opt := &Opt{
+			Probe:        true,
+			ProbeBudget:  1000,
+			Vivify:       true,
+			VivifyBudget: 1000,
+			RestartFirst: 10,
+		}
+		d = New(opt)
+		addProblem(d, p)
+		if d.Solve() != sat {
+			t.Errorf("seed %d: sat %v (!= %v)", seed, !sat, sat)
+		}
+		if sat {
+			checkModel(t, "random", p, d.Model())
+		}
+	}
+}
@@ -165,6 +165,33 @@ func (o *occLists) Smudge(p Lit) {
 	}
 }
 
+// clone returns a copy of o whose watchers refer to the clauses returned by
+// clone.
+func (o *occLists) clone(clone func(*Clause) *Clause) *occLists {
+	o2 := &occLists{
+		_occs:   make([][]watcher, len(o._occs)),
+		_dirty:  append([]bool(nil), o._dirty...),
+		dirties: append([]Lit(nil), o.dirties...),
+	}
+	for i, ws := range o._occs {
+		if ws == nil {
+			continue
+		}
+		ws2 := make([]watcher, len(ws))
+		for j, w := range ws {
+			ws2[j] = watcher{clone(w.c), w.blocker}
+		}
+		o2._occs[i] = ws2
+	}
+	if o.occs != nil {
+		o2.occs = o2._occs[:len(o.occs)]
+	}
+	if o.dirty != nil {
+		o2.dirty = o2._dirty[:len(o.dirty)]
+	}
+	return o2
+}
+
 // watcher is a Clause which is blocked in model search by Lit blocker.
 type watcher struct {
 	c       *Clause